@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/export"
+	"github.com/ayn2op/discordo/internal/http"
+	"github.com/ayn2op/discordo/internal/keyring"
+)
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: discordo export [flags] <channel id>\n\n")
+		fmt.Fprintf(flags.Output(), "Write the history of a channel or thread to a file. An interrupted export resumes when run again with the same output.\n\n")
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", string(export.FormatJSON), "transcript format: json, markdown or html")
+	output := flags.String("output", "", "path of the transcript (default <channel id>.<format extension>)")
+	attachments := flags.Bool("attachments", false, "download attachments next to the transcript")
+	delay := flags.Duration("delay", 500*time.Millisecond, "least time between two requests")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected exactly one channel id")
+	}
+	id, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid channel id %q: %w", flags.Arg(0), err)
+	}
+
+	opts := export.Options{
+		ChannelID:   discord.ChannelID(id),
+		Path:        *output,
+		Attachments: *attachments,
+		Delay:       *delay,
+		Progress: func(fetched int) {
+			fmt.Fprintf(os.Stderr, "\rfetched %d messages", fetched)
+		},
+	}
+	opts.Format, err = export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	if opts.Path == "" {
+		opts.Path = opts.ChannelID.String() + "." + opts.Format.Ext()
+	}
+
+	token := os.Getenv(keyring.TokenEnvVarKey)
+	if token == "" {
+		token, err = keyring.GetToken()
+		if err != nil {
+			return fmt.Errorf("failed to retrieve token from keyring; log in with the UI or set %s: %w", keyring.TokenEnvVarKey, err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = export.Run(ctx, http.NewClient(token).WithContext(ctx), opts)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("failed to export channel: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "exported to %s\n", opts.Path)
+	return nil
+}
@@ -25,6 +25,10 @@ func Run() error {
 		return nil
 	}
 
+	if flag.Arg(0) == "export" {
+		return runExport(flag.Args()[1:])
+	}
+
 	var level slog.Level
 	switch *logLevel {
 	case "debug":
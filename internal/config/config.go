@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/BurntSushi/toml"
@@ -75,6 +76,25 @@ type (
 		MaxHeight int `toml:"max_height"`
 	}
 
+	// MessagesListDisplay is how messages are laid out in the messages list.
+	MessagesListDisplay string
+
+	MessagesListConfig struct {
+		Display MessagesListDisplay `toml:"display"`
+		// GroupWindow is how long after an author's message their next one
+		// still joins its group in cozy mode.
+		GroupWindow time.Duration `toml:"group_window"`
+	}
+
+	ImagesConfig struct {
+		Enabled bool `toml:"enabled"`
+		// Protocol is "auto", "kitty", "sixel" or "halfblocks".
+		Protocol string `toml:"protocol"`
+		// MaxHeight and MaxWidth bound previews, in rows and columns.
+		MaxHeight int `toml:"max_height"`
+		MaxWidth  int `toml:"max_width"`
+	}
+
 	SidebarMarkersConfig struct {
 		Expanded  string `toml:"expanded"`
 		Collapsed string `toml:"collapsed"`
@@ -90,11 +110,21 @@ type (
 		DM       int `toml:"dm"`
 	}
 
+	// SidebarUnreadIndicator is how unread channels stand out in the guilds
+	// tree.
+	SidebarUnreadIndicator string
+
 	SidebarConfig struct {
 		// WidthPercent is the percentage of the total window width that the guilds tree (sidebar) occupies.
-		WidthPercent int                  `toml:"width_percent"`
-		Markers      SidebarMarkersConfig `toml:"markers"`
-		Indents      SidebarIndentsConfig `toml:"indents"`
+		WidthPercent    int                    `toml:"width_percent"`
+		UnreadIndicator SidebarUnreadIndicator `toml:"unread_indicator"`
+		Markers         SidebarMarkersConfig   `toml:"markers"`
+		Indents         SidebarIndentsConfig   `toml:"indents"`
+	}
+
+	MemberListConfig struct {
+		// WidthPercent is the percentage of the total window width that the member list occupies.
+		WidthPercent int `toml:"width_percent"`
 	}
 
 	Config struct {
@@ -105,20 +135,26 @@ type (
 		Status              discord.Status `toml:"status"`
 		HideBlockedUsers    bool           `toml:"hide_blocked_users"`
 		ShowAttachmentLinks bool           `toml:"show_attachment_links"`
+		// AuditMode keeps deleted messages and the previous revisions of
+		// edited messages in the messages list.
+		AuditMode bool `toml:"audit_mode"`
 
 		// Use 0 to disable
 		AutocompleteLimit uint8 `toml:"autocomplete_limit"`
 		MessagesLimit     uint8 `toml:"messages_limit"`
 
-		Markdown        MarkdownConfig  `toml:"markdown"`
-		Help            HelpConfig      `toml:"help"`
-		Picker          PickerConfig    `toml:"picker"`
-		Timestamps      Timestamps      `toml:"timestamps"`
-		DateSeparator   DateSeparator   `toml:"date_separator"`
-		Notifications   Notifications   `toml:"notifications"`
-		TypingIndicator TypingIndicator `toml:"typing_indicator"`
-		Sidebar         SidebarConfig   `toml:"sidebar"`
-		Composer        ComposerConfig  `toml:"composer"`
+		Markdown        MarkdownConfig     `toml:"markdown"`
+		Help            HelpConfig         `toml:"help"`
+		Picker          PickerConfig       `toml:"picker"`
+		Timestamps      Timestamps         `toml:"timestamps"`
+		DateSeparator   DateSeparator      `toml:"date_separator"`
+		Notifications   Notifications      `toml:"notifications"`
+		TypingIndicator TypingIndicator    `toml:"typing_indicator"`
+		Sidebar         SidebarConfig      `toml:"sidebar"`
+		MemberList      MemberListConfig   `toml:"member_list"`
+		Composer        ComposerConfig     `toml:"composer"`
+		Images          ImagesConfig       `toml:"images"`
+		MessagesList    MessagesListConfig `toml:"messages_list"`
 
 		Icons Icons `toml:"icons"`
 
@@ -127,6 +163,35 @@ type (
 	}
 )
 
+const (
+	// DisplayCozy groups consecutive messages by one author under a single
+	// header and indents their bodies.
+	DisplayCozy MessagesListDisplay = "cozy"
+	// DisplayCompact prefixes every message with its timestamp and author.
+	DisplayCompact MessagesListDisplay = "compact"
+	// DisplayIRC prefixes every message with its timestamp and "<author>".
+	DisplayIRC MessagesListDisplay = "irc"
+)
+
+const (
+	// UnreadBadges draws the number of unread mentions after the name.
+	UnreadBadges SidebarUnreadIndicator = "badges"
+	// UnreadStyles draws unread items in bold and mentioned ones underlined.
+	UnreadStyles SidebarUnreadIndicator = "styles"
+	// UnreadBoth does both.
+	UnreadBoth SidebarUnreadIndicator = "both"
+)
+
+// Badges reports whether mention counts are drawn.
+func (i SidebarUnreadIndicator) Badges() bool {
+	return i == UnreadBadges || i == UnreadBoth
+}
+
+// Styles reports whether unread items are styled.
+func (i SidebarUnreadIndicator) Styles() bool {
+	return i == UnreadStyles || i == UnreadBoth
+}
+
 //go:embed config.toml
 var defaultCfg []byte
 
@@ -182,6 +247,28 @@ func applyDefaults(cfg *Config) {
 		cfg.Composer.MaxHeight = 10
 	}
 
+	switch cfg.MessagesList.Display {
+	case DisplayCozy, DisplayCompact, DisplayIRC:
+	default:
+		cfg.MessagesList.Display = DisplayCompact
+	}
+	if cfg.MessagesList.GroupWindow <= 0 {
+		cfg.MessagesList.GroupWindow = 7 * time.Minute
+	}
+
+	if cfg.Images.MaxHeight <= 0 {
+		cfg.Images.MaxHeight = 12
+	}
+	if cfg.Images.MaxWidth <= 0 {
+		cfg.Images.MaxWidth = 48
+	}
+
+	switch cfg.Sidebar.UnreadIndicator {
+	case UnreadBadges, UnreadStyles, UnreadBoth:
+	default:
+		cfg.Sidebar.UnreadIndicator = UnreadBoth
+	}
+
 	if cfg.Sidebar.WidthPercent <= 0 || cfg.Sidebar.WidthPercent >= 100 {
 		// these guidelines are simply to guarantee functionality;
 		// there's no guarantee that there's functional utility in
@@ -189,6 +276,9 @@ func applyDefaults(cfg *Config) {
 		// user to decide.
 		cfg.Sidebar.WidthPercent = 20
 	}
+	if cfg.MemberList.WidthPercent <= 0 || cfg.MemberList.WidthPercent >= 100 {
+		cfg.MemberList.WidthPercent = 15
+	}
 
 	if cfg.DateSeparator.Format == "" {
 		cfg.DateSeparator.Format = "January 2, 2006"
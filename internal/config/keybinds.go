@@ -60,8 +60,11 @@ type PickerKeybinds struct {
 
 type GuildsTreeKeybinds struct {
 	SelectionKeybinds
-	SelectCurrent Keybind `toml:"select_current"`
-	YankID        Keybind `toml:"yank_id"`
+	SelectCurrent        Keybind `toml:"select_current"`
+	YankID               Keybind `toml:"yank_id"`
+	NotificationSettings Keybind `toml:"notification_settings"`
+	MarkRead             Keybind `toml:"mark_read"`
+	MarkAllRead          Keybind `toml:"mark_all_read"`
 
 	CollapseAll        Keybind `toml:"collapse_all"`
 	CollapseParentNode Keybind `toml:"collapse_parent_node"`
@@ -72,9 +75,12 @@ type MessagesListKeybinds struct {
 	SelectionKeybinds
 	ScrollKeybinds
 
-	SelectReply  Keybind `toml:"select_reply"`
-	Reply        Keybind `toml:"reply"`
-	ReplyMention Keybind `toml:"reply_mention"`
+	SelectReply   Keybind `toml:"select_reply"`
+	JumpToUnread  Keybind `toml:"jump_to_unread"`
+	MarkUnread    Keybind `toml:"mark_unread"`
+	JumpToPresent Keybind `toml:"jump_to_present"`
+	Reply         Keybind `toml:"reply"`
+	ReplyMention  Keybind `toml:"reply_mention"`
 
 	Cancel        Keybind `toml:"cancel"`
 	Edit          Keybind `toml:"edit"`
@@ -82,6 +88,28 @@ type MessagesListKeybinds struct {
 	DeleteConfirm Keybind `toml:"delete_confirm"`
 	Open          Keybind `toml:"open"`
 
+	AddReaction    Keybind `toml:"add_reaction"`
+	ToggleReaction Keybind `toml:"toggle_reaction"`
+
+	Vote        Keybind `toml:"vote"`
+	RemoveVotes Keybind `toml:"remove_votes"`
+
+	ShowPins  Keybind `toml:"show_pins"`
+	TogglePin Keybind `toml:"toggle_pin"`
+
+	ToggleThread           Keybind `toml:"toggle_thread"`
+	StartThread            Keybind `toml:"start_thread"`
+	ToggleThreadMembership Keybind `toml:"toggle_thread_membership"`
+	ToggleThreadArchived   Keybind `toml:"toggle_thread_archived"`
+
+	ShowRevisions  Keybind `toml:"show_revisions"`
+	Interact       Keybind `toml:"interact"`
+	ToggleSpoilers Keybind `toml:"toggle_spoilers"`
+
+	VisualMode Keybind `toml:"visual_mode"`
+	ToggleMark Keybind `toml:"toggle_mark"`
+	Export     Keybind `toml:"export"`
+
 	YankContent Keybind `toml:"yank_content"`
 	YankURL     Keybind `toml:"yank_url"`
 	YankID      Keybind `toml:"yank_id"`
@@ -103,9 +131,21 @@ type MentionsListKeybinds struct {
 	SelectionKeybinds
 }
 
+type MemberListKeybinds struct {
+	SelectionKeybinds
+	SelectCurrent Keybind `toml:"select_current"`
+	Mention       Keybind `toml:"mention"`
+	DirectMessage Keybind `toml:"direct_message"`
+}
+
 type Keybinds struct {
 	ToggleGuildsTree     Keybind `toml:"toggle_guilds_tree"`
 	ToggleChannelsPicker Keybind `toml:"toggle_channels_picker"`
+	ToggleSearch         Keybind `toml:"toggle_search"`
+	ToggleMemberList     Keybind `toml:"toggle_member_list"`
+	GoToLink             Keybind `toml:"go_to_link"`
+	NextUnread           Keybind `toml:"next_unread"`
+	NextMention          Keybind `toml:"next_mention"`
 	ToggleHelp           Keybind `toml:"toggle_help"`
 	Suspend              Keybind `toml:"suspend"`
 
@@ -121,6 +161,7 @@ type Keybinds struct {
 	MessagesList MessagesListKeybinds `toml:"messages_list"`
 	Composer     ComposerKeybinds     `toml:"composer"`
 	MentionsList MentionsListKeybinds `toml:"mentions_list"`
+	MemberList   MemberListKeybinds   `toml:"member_list"`
 
 	Logout Keybind `toml:"logout"`
 	Quit   Keybind `toml:"quit"`
@@ -145,9 +186,12 @@ func defaultPickerKeybinds() PickerKeybinds {
 
 func defaultGuildsTreeKeybinds() GuildsTreeKeybinds {
 	return GuildsTreeKeybinds{
-		SelectionKeybinds: defaultSelectionKeybinds(),
-		SelectCurrent:     desc("select"),
-		YankID:            desc("copy id"),
+		SelectionKeybinds:    defaultSelectionKeybinds(),
+		SelectCurrent:        desc("select"),
+		YankID:               desc("copy id"),
+		NotificationSettings: desc("notifications"),
+		MarkRead:             desc("mark read"),
+		MarkAllRead:          desc("mark all read"),
 
 		CollapseAll:        desc("collapse all"),
 		CollapseParentNode: desc("collapse parent"),
@@ -157,22 +201,41 @@ func defaultGuildsTreeKeybinds() GuildsTreeKeybinds {
 
 func defaultMessagesListKeybinds() MessagesListKeybinds {
 	return MessagesListKeybinds{
-		SelectionKeybinds: defaultSelectionKeybinds(),
-		ScrollUp:          desc("scr up"),
-		ScrollDown:        desc("scr down"),
-		ScrollTop:         desc("scr top"),
-		ScrollBottom:      desc("scr btm"),
-		SelectReply:       desc("sel reply"),
-		Reply:             desc("reply"),
-		ReplyMention:      desc("@reply"),
-		Cancel:            desc("cancel"),
-		Edit:              desc("edit"),
-		Delete:            desc("force delete"),
-		DeleteConfirm:     desc("delete"),
-		Open:              desc("open"),
-		YankContent:       desc("copy text"),
-		YankURL:           desc("copy url"),
-		YankID:            desc("copy id"),
+		SelectionKeybinds:      defaultSelectionKeybinds(),
+		ScrollUp:               desc("scr up"),
+		ScrollDown:             desc("scr down"),
+		ScrollTop:              desc("scr top"),
+		ScrollBottom:           desc("scr btm"),
+		SelectReply:            desc("sel reply"),
+		JumpToUnread:           desc("unread"),
+		MarkUnread:             desc("mark unread"),
+		JumpToPresent:          desc("present"),
+		Reply:                  desc("reply"),
+		ReplyMention:           desc("@reply"),
+		Cancel:                 desc("cancel"),
+		Edit:                   desc("edit"),
+		Delete:                 desc("force delete"),
+		DeleteConfirm:          desc("delete"),
+		Open:                   desc("open"),
+		AddReaction:            desc("react"),
+		ToggleReaction:         desc("toggle reaction"),
+		Vote:                   desc("vote"),
+		RemoveVotes:            desc("remove votes"),
+		ShowPins:               desc("pins"),
+		TogglePin:              desc("pin/unpin"),
+		ToggleThread:           desc("thread"),
+		StartThread:            desc("new thread"),
+		ToggleThreadMembership: desc("join/leave thread"),
+		ToggleThreadArchived:   desc("archive thread"),
+		ShowRevisions:          desc("edit history"),
+		Interact:               desc("components"),
+		ToggleSpoilers:         desc("spoilers"),
+		VisualMode:             desc("visual"),
+		ToggleMark:             desc("mark"),
+		Export:                 desc("export"),
+		YankContent:            desc("copy text"),
+		YankURL:                desc("copy url"),
+		YankID:                 desc("copy id"),
 	}
 }
 
@@ -195,10 +258,24 @@ func defaultMentionsListKeybinds() MentionsListKeybinds {
 	}
 }
 
+func defaultMemberListKeybinds() MemberListKeybinds {
+	return MemberListKeybinds{
+		SelectionKeybinds: defaultSelectionKeybinds(),
+		SelectCurrent:     desc("profile"),
+		Mention:           desc("mention"),
+		DirectMessage:     desc("message"),
+	}
+}
+
 func defaultKeybinds() Keybinds {
 	return Keybinds{
 		ToggleGuildsTree:     desc("toggle guilds"),
 		ToggleChannelsPicker: desc("channels picker"),
+		ToggleSearch:         desc("search"),
+		ToggleMemberList:     desc("members"),
+		GoToLink:             desc("go to link"),
+		NextUnread:           desc("next unread"),
+		NextMention:          desc("next mention"),
 		ToggleHelp:           desc("help"),
 		Suspend:              desc("suspend"),
 
@@ -217,5 +294,6 @@ func defaultKeybinds() Keybinds {
 		MessagesList: defaultMessagesListKeybinds(),
 		Composer:     defaultComposerKeybinds(),
 		MentionsList: defaultMentionsListKeybinds(),
+		MemberList:   defaultMemberListKeybinds(),
 	}
 }
@@ -216,23 +216,77 @@ type (
 		IdleStyle    StyleWrapper `toml:"idle_style"`
 		DNDStyle     StyleWrapper `toml:"dnd_style"`
 		OfflineStyle StyleWrapper `toml:"offline_style"`
+
+		MentionBadgeStyle StyleWrapper `toml:"mention_badge_style"`
 	}
 
 	MessagesListTheme struct {
 		ReplyIndicator     string       `toml:"reply_indicator"`
 		ForwardedIndicator string       `toml:"forwarded_indicator"`
+		QuoteIndicator     string       `toml:"quote_indicator"`
 		AuthorStyle        StyleWrapper `toml:"author_style"`
 		MentionStyle       StyleWrapper `toml:"mention_style"`
 		EmojiStyle         StyleWrapper `toml:"emoji_style"`
 		URLStyle           StyleWrapper `toml:"url_style"`
+		TimestampStyle     StyleWrapper `toml:"timestamp_style"`
+		QuoteStyle         StyleWrapper `toml:"quote_style"`
+		SubtextStyle       StyleWrapper `toml:"subtext_style"`
+		Heading1Style      StyleWrapper `toml:"heading1_style"`
+		Heading2Style      StyleWrapper `toml:"heading2_style"`
+		Heading3Style      StyleWrapper `toml:"heading3_style"`
 		AttachmentStyle    StyleWrapper `toml:"attachment_style"`
 		ReactionStyle      StyleWrapper `toml:"reaction_style"`
 		OwnReactionStyle   StyleWrapper `toml:"own_reaction_style"`
 
+		UnreadSeparatorStyle    StyleWrapper `toml:"unread_separator_style"`
+		HighlightedMessageStyle StyleWrapper `toml:"highlighted_message_style"`
+		ThreadStyle             StyleWrapper `toml:"thread_style"`
+		PollBarStyle            StyleWrapper `toml:"poll_bar_style"`
+		PollOwnVoteStyle        StyleWrapper `toml:"poll_own_vote_style"`
+		MarkedMessageStyle      StyleWrapper `toml:"marked_message_style"`
+		DeletedMessageStyle     StyleWrapper `toml:"deleted_message_style"`
+		DiffAddedStyle          StyleWrapper `toml:"diff_added_style"`
+		DiffRemovedStyle        StyleWrapper `toml:"diff_removed_style"`
+
 		MessageStyle         StyleWrapper `toml:"message_style"`
 		SelectedMessageStyle StyleWrapper `toml:"selected_message_style"`
 
-		Embeds MessagesListEmbedsTheme `toml:"embeds"`
+		Embeds     MessagesListEmbedsTheme     `toml:"embeds"`
+		System     MessagesListSystemTheme     `toml:"system"`
+		Components MessagesListComponentsTheme `toml:"components"`
+	}
+
+	// MessagesListComponentsTheme styles the buttons and select menus of
+	// application messages.
+	MessagesListComponentsTheme struct {
+		PrimaryStyle   StyleWrapper `toml:"primary_style"`
+		SecondaryStyle StyleWrapper `toml:"secondary_style"`
+		SuccessStyle   StyleWrapper `toml:"success_style"`
+		DangerStyle    StyleWrapper `toml:"danger_style"`
+		LinkStyle      StyleWrapper `toml:"link_style"`
+		SelectStyle    StyleWrapper `toml:"select_style"`
+		// EphemeralStyle is the "Only you can see this" notice.
+		EphemeralStyle StyleWrapper `toml:"ephemeral_style"`
+	}
+
+	// MessagesListSystemTheme styles the messages Discord generates for
+	// events, such as members joining or channels being renamed.
+	MessagesListSystemTheme struct {
+		JoinStyle         StyleWrapper `toml:"join_style"`
+		RecipientStyle    StyleWrapper `toml:"recipient_style"`
+		CallStyle         StyleWrapper `toml:"call_style"`
+		ChannelStyle      StyleWrapper `toml:"channel_style"`
+		PinStyle          StyleWrapper `toml:"pin_style"`
+		BoostStyle        StyleWrapper `toml:"boost_style"`
+		FollowStyle       StyleWrapper `toml:"follow_style"`
+		DiscoveryStyle    StyleWrapper `toml:"discovery_style"`
+		ThreadStyle       StyleWrapper `toml:"thread_style"`
+		AutoModStyle      StyleWrapper `toml:"automod_style"`
+		StageStyle        StyleWrapper `toml:"stage_style"`
+		SubscriptionStyle StyleWrapper `toml:"subscription_style"`
+		IncidentStyle     StyleWrapper `toml:"incident_style"`
+		PollResultStyle   StyleWrapper `toml:"poll_result_style"`
+		UnknownStyle      StyleWrapper `toml:"unknown_style"`
 	}
 
 	MessagesListEmbedsTheme struct {
@@ -251,6 +305,11 @@ type (
 		MaxHeight uint `toml:"max_height"`
 	}
 
+	MemberListTheme struct {
+		GroupStyle    StyleWrapper `toml:"group_style"`
+		ActivityStyle StyleWrapper `toml:"activity_style"`
+	}
+
 	DialogTheme struct {
 		Style           StyleWrapper `toml:"style"`
 		BackgroundStyle StyleWrapper `toml:"background_style"`
@@ -271,6 +330,7 @@ type (
 		ScrollBar    ScrollBarTheme    `toml:"scroll_bar"`
 		MessagesList MessagesListTheme `toml:"messages_list"`
 		MentionsList MentionsListTheme `toml:"mentions_list"`
+		MemberList   MemberListTheme   `toml:"member_list"`
 		Dialog       DialogTheme       `toml:"dialog"`
 		Help         HelpTheme         `toml:"help"`
 	}
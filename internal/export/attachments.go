@@ -0,0 +1,128 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+)
+
+// maxDownloadAttempts bounds the retries of a download that keeps being rate
+// limited.
+const maxDownloadAttempts = 5
+
+// downloadAttachments downloads the attachments of the messages into the
+// files directory and returns their paths relative to the transcript.
+// Attachments already downloaded by an interrupted export are kept; those that
+// fail to download are left out, so the transcript links to their URL.
+func downloadAttachments(ctx context.Context, messages []discord.Message, opts Options) (map[discord.AttachmentID]string, error) {
+	dir := opts.FilesDir()
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create files dir: %w", err)
+	}
+
+	files := make(map[discord.AttachmentID]string)
+	var last time.Time
+	for _, message := range messages {
+		for _, attachment := range message.Attachments {
+			name := attachment.ID.String() + "_" + sanitizeFilename(attachment.Filename)
+			path := filepath.Join(dir, name)
+			relPath := filepath.ToSlash(filepath.Join(filepath.Base(dir), name))
+
+			if _, err := os.Stat(path); err == nil {
+				files[attachment.ID] = relPath
+				continue
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+
+			if wait := opts.Delay - time.Since(last); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+			last = time.Now()
+
+			if err := download(ctx, attachment.URL, path); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				slog.Error("failed to download attachment", "err", err, "filename", attachment.Filename, "url", attachment.URL)
+				continue
+			}
+			files[attachment.ID] = relPath
+		}
+	}
+	return files, nil
+}
+
+// download writes the file at url to path, waiting as long as the server
+// asks when it is rate limited.
+func download(ctx context.Context, url, path string) error {
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxDownloadAttempts {
+			resp.Body.Close()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryAfter(resp.Header, attempt)):
+			}
+			continue
+		}
+
+		err = writeFile(path, func(w io.Writer) error {
+			if resp.StatusCode != http.StatusOK {
+				return errors.New(resp.Status)
+			}
+			_, err := io.Copy(w, resp.Body)
+			return err
+		})
+		resp.Body.Close()
+		return err
+	}
+}
+
+// retryAfter returns how long the Retry-After header asks to wait, backing
+// off exponentially when it is missing.
+func retryAfter(header http.Header, attempt int) time.Duration {
+	if seconds, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return time.Second << (attempt - 1)
+}
+
+// sanitizeFilename replaces the characters that are not allowed in file
+// names on common file systems.
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "file"
+	}
+	return name
+}
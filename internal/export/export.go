@@ -0,0 +1,239 @@
+// Package export writes the history of a channel or thread to a JSON,
+// Markdown or HTML transcript.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+)
+
+// pageSize is the most messages Discord returns per request.
+const pageSize = 100
+
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case FormatJSON, FormatMarkdown, FormatHTML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q; expected json, markdown or html", s)
+	}
+}
+
+// Ext returns the file extension of the format, without the dot.
+func (f Format) Ext() string {
+	switch f {
+	case FormatMarkdown:
+		return "md"
+	case FormatHTML:
+		return "html"
+	default:
+		return "json"
+	}
+}
+
+// Client is the part of the API client used to export a channel.
+type Client interface {
+	Channel(channelID discord.ChannelID) (*discord.Channel, error)
+	MessagesBefore(channelID discord.ChannelID, before discord.MessageID, limit uint) ([]discord.Message, error)
+}
+
+type Options struct {
+	ChannelID discord.ChannelID
+	Format    Format
+	// Path is the transcript file. Attachments are downloaded to FilesDir.
+	Path        string
+	Attachments bool
+	// Delay is the least time between two requests on top of the rate limits
+	// the client already waits for, so that long exports do not hit them.
+	Delay time.Duration
+	// Progress, when not nil, is called with the number of messages fetched
+	// so far after every page.
+	Progress func(fetched int)
+}
+
+// FilesDir returns the directory attachments are downloaded to: the
+// transcript path without its extension, followed by "_files".
+func (o Options) FilesDir() string {
+	return strings.TrimSuffix(o.Path, filepath.Ext(o.Path)) + "_files"
+}
+
+// partialPath is where the messages are kept while they are fetched, newest
+// first, one JSON object per line. An interrupted export resumes from the
+// oldest message in it.
+func (o Options) partialPath() string {
+	return o.Path + ".partial"
+}
+
+// Run fetches the whole history of the channel and writes the transcript.
+// If ctx is cancelled the messages fetched so far are kept and the next run
+// with the same options resumes from them.
+func Run(ctx context.Context, client Client, opts Options) error {
+	channel, err := client.Channel(opts.ChannelID)
+	if err != nil {
+		return fmt.Errorf("failed to get channel: %w", err)
+	}
+
+	messages, err := fetchMessages(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+	slices.Reverse(messages)
+
+	t := transcript{
+		Channel:    *channel,
+		ExportedAt: time.Now(),
+		Messages:   messages,
+	}
+	if opts.Attachments {
+		t.Files, err = downloadAttachments(ctx, messages, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeFile(opts.Path, func(w io.Writer) error { return render(w, opts.Format, t) }); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	if err := os.Remove(opts.partialPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove partial export: %w", err)
+	}
+	return nil
+}
+
+// fetchMessages pages through the history of the channel from the newest
+// message to the oldest, appending every page to the partial file.
+func fetchMessages(ctx context.Context, client Client, opts Options) ([]discord.Message, error) {
+	path := opts.partialPath()
+	messages, err := readPartial(path, opts.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open partial export: %w", err)
+	}
+	defer file.Close()
+	enc := json.NewEncoder(file)
+
+	var before discord.MessageID
+	if len(messages) != 0 {
+		before = messages[len(messages)-1].ID
+	}
+
+	var last time.Time
+	for {
+		if wait := opts.Delay - time.Since(last); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+		} else if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		last = time.Now()
+
+		page, err := client.MessagesBefore(opts.ChannelID, before, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get messages: %w", err)
+		}
+
+		for _, message := range page {
+			if err := enc.Encode(message); err != nil {
+				return nil, fmt.Errorf("failed to write partial export: %w", err)
+			}
+		}
+		messages = append(messages, page...)
+		if opts.Progress != nil {
+			opts.Progress(len(messages))
+		}
+
+		if len(page) < pageSize {
+			return messages, nil
+		}
+		before = page[len(page)-1].ID
+	}
+}
+
+// readPartial returns the messages of an interrupted export, newest first. A
+// line cut short by the interruption is dropped.
+func readPartial(path string, channelID discord.ChannelID) ([]discord.Message, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open partial export: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		messages []discord.Message
+		offset   int64
+	)
+	dec := json.NewDecoder(file)
+	for {
+		var message discord.Message
+		if err := dec.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err := file.Truncate(offset); err != nil {
+				return nil, fmt.Errorf("failed to truncate partial export: %w", err)
+			}
+			break
+		}
+		if message.ChannelID != channelID {
+			return nil, fmt.Errorf("partial export %s belongs to channel %s; remove it to export channel %s", path, message.ChannelID, channelID)
+		}
+		messages = append(messages, message)
+		offset = dec.InputOffset()
+	}
+	return messages, nil
+}
+
+// writeFile writes to a temporary file that replaces path once complete, so
+// an interrupted write never leaves a truncated file behind.
+func writeFile(path string, write func(w io.Writer) error) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
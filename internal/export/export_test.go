@@ -0,0 +1,233 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+)
+
+const testChannelID discord.ChannelID = 1
+
+// fakeClient serves a history of messages with IDs 1 to n.
+type fakeClient struct {
+	n int
+	// befores records the before argument of every request.
+	befores []discord.MessageID
+	// failAfter makes the request after that many fail when non-zero.
+	failAfter int
+}
+
+func (c *fakeClient) Channel(channelID discord.ChannelID) (*discord.Channel, error) {
+	return &discord.Channel{ID: channelID, Name: "general"}, nil
+}
+
+func (c *fakeClient) MessagesBefore(channelID discord.ChannelID, before discord.MessageID, limit uint) ([]discord.Message, error) {
+	if c.failAfter != 0 && len(c.befores) == c.failAfter {
+		return nil, errors.New("boom")
+	}
+	c.befores = append(c.befores, before)
+
+	newest := discord.MessageID(c.n)
+	if before.IsValid() {
+		newest = before - 1
+	}
+
+	var messages []discord.Message
+	for id := newest; id > 0 && len(messages) < int(limit); id-- {
+		messages = append(messages, discord.Message{
+			ID:        id,
+			ChannelID: channelID,
+			Content:   "message " + id.String(),
+			Author:    discord.User{Username: "alice"},
+		})
+	}
+	return messages, nil
+}
+
+func readTranscript(t *testing.T, path string) transcript {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got transcript
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "general.json")
+	client := &fakeClient{n: 250}
+	opts := Options{ChannelID: testChannelID, Format: FormatJSON, Path: path}
+	if err := Run(context.Background(), client, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readTranscript(t, path)
+	if len(got.Messages) != 250 {
+		t.Fatalf("got %d messages, want 250", len(got.Messages))
+	}
+	for i, message := range got.Messages {
+		if message.ID != discord.MessageID(i+1) {
+			t.Fatalf("message %d: got ID %d, want %d", i, message.ID, i+1)
+		}
+	}
+	if len(client.befores) != 3 {
+		t.Fatalf("got %d requests, want 3", len(client.befores))
+	}
+	if _, err := os.Stat(opts.partialPath()); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("partial export was not removed: %v", err)
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "general.json")
+	opts := Options{ChannelID: testChannelID, Format: FormatJSON, Path: path}
+
+	client := &fakeClient{n: 250, failAfter: 2}
+	if err := Run(context.Background(), client, opts); err == nil {
+		t.Fatal("expected the interrupted export to fail")
+	}
+
+	// Simulate a line cut short by the interruption.
+	file, err := os.OpenFile(opts.partialPath(), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"id":"50","chan`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	client = &fakeClient{n: 250}
+	if err := Run(context.Background(), client, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.befores) != 1 || client.befores[0] != 51 {
+		t.Fatalf("got requests before %v, want [51]", client.befores)
+	}
+	if got := readTranscript(t, path); len(got.Messages) != 250 {
+		t.Fatalf("got %d messages, want 250", len(got.Messages))
+	}
+}
+
+func TestRunResumeOtherChannel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "general.json")
+	opts := Options{ChannelID: testChannelID, Format: FormatJSON, Path: path}
+	if err := os.WriteFile(opts.partialPath(), []byte(`{"id":"1","channel_id":"2"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Run(context.Background(), &fakeClient{n: 10}, opts); err == nil {
+		t.Fatal("expected a partial export of another channel to be rejected")
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	reply := discord.Message{ID: 1, Author: discord.User{Username: "bob"}, Content: "first line\nsecond line"}
+	tr := transcript{
+		Channel: discord.Channel{Name: "general"},
+		Messages: []discord.Message{{
+			ID:                2,
+			Author:            discord.User{Username: "alice"},
+			Content:           "hello",
+			ReferencedMessage: &reply,
+			Attachments:       []discord.Attachment{{ID: 3, Filename: "cat.png", URL: "https://cdn.example/cat.png"}},
+		}},
+		Files: map[discord.AttachmentID]string{3: "general_files/3_cat.png"},
+	}
+
+	var b strings.Builder
+	if err := renderMarkdown(&b, tr); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"# #general\n",
+		"**alice** · ",
+		"> replying to **bob**: first line\n",
+		"hello\n",
+		"- [cat.png](general_files/3_cat.png)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderHTMLEscapes(t *testing.T) {
+	tr := transcript{
+		Channel:  discord.Channel{Name: "general"},
+		Messages: []discord.Message{{ID: 1, Author: discord.User{Username: "alice"}, Content: "<script>"}},
+	}
+
+	var b strings.Builder
+	if err := render(&b, FormatHTML, tr); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); strings.Contains(got, "<script>") || !strings.Contains(got, "&lt;script&gt;") {
+		t.Fatalf("content is not escaped:\n%s", got)
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"cat.png", "cat.png"},
+		{"../../etc/passwd", ".._.._etc_passwd"},
+		{`a:b*c?.txt`, "a_b_c_.txt"},
+		{"..", "file"},
+		{"", "file"},
+	}
+
+	for _, test := range tests {
+		if got := sanitizeFilename(test.name); got != test.want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDownloadAttachmentsSkipsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "image")
+	}))
+	defer server.Close()
+
+	messages := []discord.Message{{Attachments: []discord.Attachment{
+		{ID: 1, Filename: "cat.png", URL: server.URL + "/cat.png"},
+		{ID: 2, Filename: "missing.png", URL: server.URL + "/missing.png"},
+	}}}
+	opts := Options{Path: filepath.Join(t.TempDir(), "general.json")}
+	files, err := downloadAttachments(context.Background(), messages, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := files[1], "general_files/1_cat.png"; got != want {
+		t.Errorf("files[1] = %q, want %q", got, want)
+	}
+	if path, ok := files[2]; ok {
+		t.Errorf("files[2] = %q, want no file for the failed download", path)
+	}
+	if got := (transcript{Files: files}).AttachmentURL(messages[0].Attachments[1]); got != messages[0].Attachments[1].URL {
+		t.Errorf("AttachmentURL() = %q, want the remote URL", got)
+	}
+}
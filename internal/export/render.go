@@ -0,0 +1,153 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+)
+
+const timeFormat = "2006-01-02 15:04"
+
+// transcript is what is written; it is also the schema of JSON exports.
+type transcript struct {
+	Channel    discord.Channel   `json:"channel"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Messages   []discord.Message `json:"messages"`
+	// Files maps the downloaded attachments to their paths, relative to the
+	// transcript.
+	Files map[discord.AttachmentID]string `json:"files,omitempty"`
+}
+
+func render(w io.Writer, format Format, t transcript) error {
+	switch format {
+	case FormatMarkdown:
+		return renderMarkdown(w, t)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, t)
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t)
+	}
+}
+
+func (t transcript) Title() string {
+	if t.Channel.Name != "" {
+		return "#" + t.Channel.Name
+	}
+
+	names := make([]string, len(t.Channel.DMRecipients))
+	for i, recipient := range t.Channel.DMRecipients {
+		names[i] = recipient.DisplayOrUsername()
+	}
+	if len(names) == 0 {
+		return t.Channel.ID.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// AttachmentURL returns the downloaded file of the attachment, or its URL
+// when it was not downloaded.
+func (t transcript) AttachmentURL(attachment discord.Attachment) string {
+	if path, ok := t.Files[attachment.ID]; ok {
+		return path
+	}
+	return attachment.URL
+}
+
+func formatTime(ts discord.Timestamp) string {
+	return ts.Time().Local().Format(timeFormat)
+}
+
+func renderMarkdown(w io.Writer, t transcript) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", t.Title())
+	fmt.Fprintf(&b, "Exported on %s, %d messages.\n", t.ExportedAt.Local().Format(timeFormat), len(t.Messages))
+
+	for _, message := range t.Messages {
+		fmt.Fprintf(&b, "\n**%s** · %s", message.Author.DisplayOrUsername(), formatTime(message.Timestamp))
+		if message.EditedTimestamp.IsValid() {
+			b.WriteString(" (edited)")
+		}
+		b.WriteString("\n")
+
+		if ref := message.ReferencedMessage; ref != nil {
+			content, _, _ := strings.Cut(ref.Content, "\n")
+			fmt.Fprintf(&b, "> replying to **%s**: %s\n", ref.Author.DisplayOrUsername(), content)
+		}
+		if message.Content != "" {
+			b.WriteString(message.Content)
+			b.WriteString("\n")
+		}
+		for _, embed := range message.Embeds {
+			writeMarkdownEmbed(&b, embed)
+		}
+		for _, attachment := range message.Attachments {
+			fmt.Fprintf(&b, "- [%s](%s)\n", attachment.Filename, t.AttachmentURL(attachment))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownEmbed(b *strings.Builder, embed discord.Embed) {
+	switch {
+	case embed.Title != "" && embed.URL != "":
+		fmt.Fprintf(b, "> **[%s](%s)**\n", embed.Title, embed.URL)
+	case embed.Title != "":
+		fmt.Fprintf(b, "> **%s**\n", embed.Title)
+	case embed.URL != "":
+		fmt.Fprintf(b, "> %s\n", embed.URL)
+	}
+	if embed.Description != "" {
+		fmt.Fprintf(b, "> %s\n", strings.ReplaceAll(embed.Description, "\n", "\n> "))
+	}
+	for _, field := range embed.Fields {
+		fmt.Fprintf(b, "> **%s**: %s\n", field.Name, strings.ReplaceAll(field.Value, "\n", "\n> "))
+	}
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"time": formatTime,
+	"isImage": func(attachment discord.Attachment) bool {
+		return strings.HasPrefix(attachment.ContentType, "image/")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { background: #313338; color: #dbdee1; font-family: sans-serif; margin: 0 auto; max-width: 60em; padding: 1em; }
+a { color: #00a8fc; }
+.message { padding: 0.5em 0; border-bottom: 1px solid #3f4147; }
+.author { font-weight: bold; color: #f2f3f5; }
+.meta { color: #949ba4; font-size: 0.8em; }
+.reply { color: #b5bac1; font-size: 0.9em; }
+.content { white-space: pre-wrap; overflow-wrap: anywhere; }
+.embed { border-left: 4px solid #1e1f22; background: #2b2d31; margin: 0.3em 0; padding: 0.3em 0.6em; white-space: pre-wrap; }
+img { display: block; max-width: 100%; max-height: 30em; margin: 0.3em 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Exported on {{.ExportedAt.Local.Format "2006-01-02 15:04"}}, {{len .Messages}} messages.</p>
+{{range .Messages}}<div class="message" id="{{.ID}}">
+{{with .ReferencedMessage}}<div class="reply">replying to <a href="#{{.ID}}">{{.Author.DisplayOrUsername}}</a>: {{.Content}}</div>
+{{end}}<span class="author">{{.Author.DisplayOrUsername}}</span> <span class="meta">{{time .Timestamp}}{{if .EditedTimestamp.IsValid}} (edited){{end}}</span>
+{{if .Content}}<div class="content">{{.Content}}</div>
+{{end}}{{range .Embeds}}<div class="embed">{{if .Title}}<strong>{{if .URL}}<a href="{{.URL}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</strong>
+{{end}}{{.Description}}{{range .Fields}}
+<strong>{{.Name}}</strong>: {{.Value}}{{end}}</div>
+{{end}}{{range .Attachments}}{{$url := $.AttachmentURL .}}{{if isImage .}}<a href="{{$url}}"><img src="{{$url}}" alt="{{.Filename}}"></a>
+{{else}}<div><a href="{{$url}}">{{.Filename}}</a></div>
+{{end}}{{end}}</div>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,273 @@
+package images
+
+import (
+	"bytes"
+	"cmp"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// maxConcurrentDownloads bounds the downloads started when a channel with
+	// many images is opened.
+	maxConcurrentDownloads = 4
+	// downloadTimeout bounds every download so that a stalled fetch gives its
+	// slot back.
+	downloadTimeout = 30 * time.Second
+	// maxDecodedImages is the number of decoded images kept in memory.
+	maxDecodedImages = 128
+	// maxDiskCacheSize is the size of the cache dir, in bytes, above which the
+	// least recently used files are removed.
+	maxDiskCacheSize = 64 << 20
+	// failedRetryDelay is how long an image that failed to load is not
+	// fetched again.
+	failedRetryDelay = 5 * time.Minute
+)
+
+var httpClient = &http.Client{Timeout: downloadTimeout}
+
+type cachedImage struct {
+	url string
+	img image.Image
+}
+
+// Cache downloads images into a directory and keeps the most recently used
+// decoded images in memory. Images are looked up by URL.
+type Cache struct {
+	dir    string
+	loaded chan string
+	sem    chan struct{}
+
+	mu sync.Mutex
+	// images holds the elements of recent, which is ordered from the most to
+	// the least recently used image.
+	images  map[string]*list.Element
+	recent  *list.List
+	pending map[string]struct{}
+	// failed holds when the images that failed to load failed.
+	failed   map[string]time.Time
+	diskSize int64
+}
+
+func NewCache(dir string) *Cache {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		slog.Error("failed to create images cache dir", "err", err, "path", dir)
+	}
+
+	c := &Cache{
+		dir:     dir,
+		loaded:  make(chan string),
+		sem:     make(chan struct{}, maxConcurrentDownloads),
+		images:  make(map[string]*list.Element),
+		recent:  list.New(),
+		pending: make(map[string]struct{}),
+		failed:  make(map[string]time.Time),
+	}
+	go c.pruneDisk()
+	return c
+}
+
+// Loaded receives the URL of every image that finished loading.
+func (c *Cache) Loaded() <-chan string {
+	return c.loaded
+}
+
+// Get returns the image at url. Images that are not loaded yet are fetched in
+// the background and reported on Loaded; images that failed are retried after
+// failedRetryDelay.
+func (c *Cache) Get(url string) (image.Image, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if img, ok := c.cached(url); ok {
+		return img, true
+	}
+	if _, ok := c.pending[url]; ok {
+		return nil, false
+	}
+	if failedAt, ok := c.failed[url]; ok && time.Since(failedAt) < failedRetryDelay {
+		return nil, false
+	}
+	delete(c.failed, url)
+
+	c.pending[url] = struct{}{}
+	go c.fetch(url)
+	return nil, false
+}
+
+// cached returns the decoded image and marks it as the most recently used.
+// c.mu must be held.
+func (c *Cache) cached(url string) (image.Image, bool) {
+	elem, ok := c.images[url]
+	if !ok {
+		return nil, false
+	}
+	c.recent.MoveToFront(elem)
+	return elem.Value.(cachedImage).img, true
+}
+
+// store keeps the decoded image, dropping the least recently used one when
+// there are too many. c.mu must be held.
+func (c *Cache) store(url string, img image.Image) {
+	c.images[url] = c.recent.PushFront(cachedImage{url, img})
+	if c.recent.Len() > maxDecodedImages {
+		oldest := c.recent.Remove(c.recent.Back()).(cachedImage)
+		delete(c.images, oldest.url)
+	}
+}
+
+// fail records that the image failed to load, dropping the failures that
+// expired so that the map only holds recent ones. c.mu must be held.
+func (c *Cache) fail(url string) {
+	now := time.Now()
+	for u, failedAt := range c.failed {
+		if now.Sub(failedAt) >= failedRetryDelay {
+			delete(c.failed, u)
+		}
+	}
+	c.failed[url] = now
+}
+
+func (c *Cache) fetch(url string) {
+	c.sem <- struct{}{}
+	img, err := c.load(url)
+	<-c.sem
+
+	c.mu.Lock()
+	delete(c.pending, url)
+	if err != nil {
+		c.fail(url)
+		c.mu.Unlock()
+		slog.Error("failed to load image", "err", err, "url", url)
+		return
+	}
+	c.store(url, img)
+	c.mu.Unlock()
+
+	c.loaded <- url
+}
+
+// load reads the image from the cache dir, downloading it on a miss.
+func (c *Cache) load(url string) (image.Image, error) {
+	sum := sha256.Sum256([]byte(url))
+	path := filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		data, err = download(url)
+		if err != nil {
+			return nil, err
+		}
+		c.write(path, data)
+	case err != nil:
+		return nil, err
+	default:
+		// The modification time orders the files by last use for pruneDisk.
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			slog.Warn("failed to touch cached image", "err", err, "path", path)
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+func (c *Cache) write(path string, data []byte) {
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.Warn("failed to cache image", "err", err, "path", path)
+		return
+	}
+
+	c.mu.Lock()
+	c.diskSize += int64(len(data))
+	full := c.diskSize > maxDiskCacheSize
+	c.mu.Unlock()
+	if full {
+		c.pruneDisk()
+	}
+}
+
+// pruneDisk removes the least recently used files until the cache dir is
+// below three quarters of maxDiskCacheSize, so that it is not pruned again
+// on every download.
+func (c *Cache) pruneDisk() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		slog.Error("failed to read images cache dir", "err", err, "path", c.dir)
+		return
+	}
+
+	infos := make([]fs.FileInfo, 0, len(entries))
+	var size int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		infos = append(infos, info)
+		size += info.Size()
+	}
+
+	if size > maxDiskCacheSize {
+		slices.SortFunc(infos, func(a, b fs.FileInfo) int {
+			return cmp.Compare(a.ModTime().UnixNano(), b.ModTime().UnixNano())
+		})
+		for _, info := range infos {
+			if size <= maxDiskCacheSize*3/4 {
+				break
+			}
+			path := filepath.Join(c.dir, info.Name())
+			if err := os.Remove(path); err != nil {
+				slog.Warn("failed to remove cached image", "err", err, "path", path)
+				continue
+			}
+			size -= info.Size()
+		}
+	}
+
+	c.mu.Lock()
+	c.diskSize = size
+	c.mu.Unlock()
+}
+
+func download(url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
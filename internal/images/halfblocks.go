@@ -0,0 +1,82 @@
+package images
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/ayn2op/tview"
+	"github.com/gdamore/tcell/v3"
+)
+
+// Fit returns the size in cells of a preview of a width×height pixel image
+// that fits in maxCols×maxRows. Cells are assumed to be twice as tall as they
+// are wide.
+func Fit(width, height, maxCols, maxRows int) (cols, rows int) {
+	if width <= 0 || height <= 0 || maxCols <= 0 || maxRows <= 0 {
+		return 0, 0
+	}
+
+	cols = min(maxCols, width)
+	rows = (cols*height + width) / (2 * width)
+	if rows > maxRows {
+		rows = maxRows
+		cols = rows * 2 * width / height
+	}
+	return max(cols, 1), max(rows, 1)
+}
+
+// HalfBlocks draws img in cols×rows cells. Each cell shows two pixels: the
+// upper one as the foreground of "▀" and the lower one as its background.
+func HalfBlocks(img image.Image, cols, rows int) []tview.Line {
+	scaled := scale(img, cols, rows*2)
+	builder := tview.NewLineBuilder()
+	for y := range rows {
+		if y > 0 {
+			builder.NewLine()
+		}
+		for x := range cols {
+			top := scaled.RGBAAt(x, y*2)
+			bottom := scaled.RGBAAt(x, y*2+1)
+			style := tcell.StyleDefault.Foreground(rgb(top)).Background(rgb(bottom))
+			builder.Write("▀", style)
+		}
+	}
+	return builder.Finish()
+}
+
+func rgb(c color.RGBA) tcell.Color {
+	return tcell.NewRGBColor(int32(c.R), int32(c.G), int32(c.B))
+}
+
+// scale resizes img to width×height by averaging the source pixels covered
+// by each destination pixel.
+func scale(img image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 {
+		return dst
+	}
+
+	for y := range height {
+		y0 := bounds.Min.Y + y*srcH/height
+		y1 := max(bounds.Min.Y+(y+1)*srcH/height, y0+1)
+		for x := range width {
+			x0 := bounds.Min.X + x*srcW/width
+			x1 := max(bounds.Min.X+(x+1)*srcW/width, x0+1)
+
+			var r, g, b, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, _ := img.At(sx, sy).RGBA()
+					r += pr >> 8
+					g += pg >> 8
+					b += pb >> 8
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 0xff})
+		}
+	}
+	return dst
+}
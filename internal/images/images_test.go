@@ -0,0 +1,186 @@
+package images
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol Protocol
+		env      map[string]string
+		want     Protocol
+	}{
+		{"explicit", ProtocolHalfBlocks, map[string]string{"KITTY_WINDOW_ID": "1"}, ProtocolHalfBlocks},
+		{"kitty", ProtocolAuto, map[string]string{"KITTY_WINDOW_ID": "1"}, ProtocolKitty},
+		{"kitty term", ProtocolAuto, map[string]string{"TERM": "xterm-kitty"}, ProtocolKitty},
+		{"ghostty", ProtocolAuto, map[string]string{"TERM_PROGRAM": "ghostty"}, ProtocolKitty},
+		{"wezterm", ProtocolAuto, map[string]string{"TERM_PROGRAM": "WezTerm"}, ProtocolSixel},
+		{"windows terminal", ProtocolAuto, map[string]string{"WT_SESSION": "1"}, ProtocolSixel},
+		{"foot", ProtocolAuto, map[string]string{"TERM": "foot-extra"}, ProtocolSixel},
+		{"tmux", ProtocolAuto, map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux"}, ProtocolHalfBlocks},
+		{"other", ProtocolAuto, map[string]string{"TERM": "xterm-256color"}, ProtocolHalfBlocks},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Detect(test.protocol, func(key string) string { return test.env[key] })
+			if got != test.want {
+				t.Fatalf("got = %q, want = %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name                           string
+		width, height, maxCols, maxRow int
+		wantCols, wantRows             int
+	}{
+		{"landscape", 1000, 500, 40, 20, 40, 10},
+		{"portrait", 500, 1000, 40, 10, 10, 10},
+		{"small", 8, 8, 40, 20, 8, 4},
+		{"no size", 0, 0, 40, 20, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cols, rows := Fit(test.width, test.height, test.maxCols, test.maxRow)
+			if cols != test.wantCols || rows != test.wantRows {
+				t.Fatalf("got = %dx%d, want = %dx%d", cols, rows, test.wantCols, test.wantRows)
+			}
+		})
+	}
+}
+
+func TestScale(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := range 2 {
+		for y := range 2 {
+			src.SetRGBA(x, y, color.RGBA{R: 0xff, A: 0xff})
+			src.SetRGBA(x+2, y, color.RGBA{B: 0xff, A: 0xff})
+		}
+	}
+
+	dst := scale(src, 2, 1)
+	if got, want := dst.RGBAAt(0, 0), (color.RGBA{R: 0xff, A: 0xff}); got != want {
+		t.Fatalf("left: got = %v, want = %v", got, want)
+	}
+	if got, want := dst.RGBAAt(1, 0), (color.RGBA{B: 0xff, A: 0xff}); got != want {
+		t.Fatalf("right: got = %v, want = %v", got, want)
+	}
+}
+
+func TestEncodeSixel(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	tests := []struct {
+		name   string
+		pixels []color.RGBA
+		want   string
+	}{
+		{"colours", []color.RGBA{red, red, blue}, "#5??@$#180@@-"},
+		{"run", []color.RGBA{red, red, red, red, red}, "#180!5@-"},
+		{"transparent", []color.RGBA{red, {}, red}, "#180@?@-"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, len(test.pixels), 1))
+			for x, c := range test.pixels {
+				img.SetRGBA(x, 0, c)
+			}
+
+			got := string(encodeSixel(img))
+			header := "\x1bP0;1;0q\"1;1;" + strconv.Itoa(len(test.pixels)) + ";1"
+			if !strings.HasPrefix(got, header) {
+				t.Errorf("encodeSixel() = %q, want prefix %q", got, header)
+			}
+			if want := test.want + "\x1b\\"; !strings.HasSuffix(got, want) {
+				t.Errorf("encodeSixel() = %q, want suffix %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewCache(t.TempDir())
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range maxDecodedImages {
+		c.store(strconv.Itoa(i), img)
+	}
+	// Using the oldest image makes the second one the least recently used.
+	if _, ok := c.cached("0"); !ok {
+		t.Fatal("image 0 is not cached")
+	}
+	c.store("new", img)
+
+	if _, ok := c.cached("1"); ok {
+		t.Fatal("image 1 was not evicted")
+	}
+	for _, url := range []string{"0", "2", "new"} {
+		if _, ok := c.cached(url); !ok {
+			t.Fatalf("image %s was evicted", url)
+		}
+	}
+}
+
+func TestCacheDropsExpiredFailures(t *testing.T) {
+	c := NewCache(t.TempDir())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failed["old"] = time.Now().Add(-failedRetryDelay)
+	c.fail("new")
+
+	if _, ok := c.failed["old"]; ok {
+		t.Fatal("expired failure was not dropped")
+	}
+	if _, ok := c.failed["new"]; !ok {
+		t.Fatal("failure was not recorded")
+	}
+}
+
+func TestPruneDisk(t *testing.T) {
+	dir := t.TempDir()
+	const fileSize = maxDiskCacheSize / 4
+	now := time.Now()
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Truncate(path, fileSize); err != nil {
+			t.Fatal(err)
+		}
+		// a is the least recently used file.
+		modTime := now.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &Cache{dir: dir}
+	c.pruneDisk()
+
+	for name, want := range map[string]bool{"a": false, "b": false, "c": true, "d": true, "e": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s: exists = %v, want = %v", name, got, want)
+		}
+	}
+	if c.diskSize != 3*fileSize {
+		t.Errorf("disk size: got = %d, want = %d", c.diskSize, 3*fileSize)
+	}
+}
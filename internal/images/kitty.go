@@ -0,0 +1,147 @@
+package images
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/ayn2op/tview"
+	"github.com/gdamore/tcell/v3"
+)
+
+const (
+	kittyPlaceholder = "\U0010EEEE"
+	kittyChunkSize   = 4096
+	// kittyMaxImages is the number of images kept in the terminal; the least
+	// recently drawn ones are deleted from it.
+	kittyMaxImages = 128
+)
+
+// kittyDiacritics encode the row and column of a placeholder cell, in the
+// order defined by the kitty graphics protocol.
+var kittyDiacritics = []rune{
+	0x0305, 0x030D, 0x030E, 0x0310, 0x0312, 0x033D, 0x033E, 0x033F,
+	0x0346, 0x034A, 0x034B, 0x034C, 0x0350, 0x0351, 0x0352, 0x0357,
+	0x035B, 0x0363, 0x0364, 0x0365, 0x0366, 0x0367, 0x0368, 0x0369,
+	0x036A, 0x036B, 0x036C, 0x036D, 0x036E, 0x036F, 0x0483, 0x0484,
+	0x0485, 0x0486, 0x0487, 0x0592, 0x0593, 0x0594, 0x0595, 0x0597,
+	0x0598, 0x0599, 0x059C, 0x059D, 0x059E, 0x059F, 0x05A0, 0x05A1,
+}
+
+// KittyMaxRows is the tallest image that can be addressed with placeholders.
+var KittyMaxRows = len(kittyDiacritics)
+
+// imageKey identifies an image sent to the terminal at a size.
+type imageKey struct {
+	url        string
+	cols, rows int
+}
+
+type kittyImage struct {
+	key imageKey
+	id  uint32
+}
+
+// Kitty sends images to the terminal with the kitty graphics protocol and
+// draws them with Unicode placeholders. The terminal replaces placeholder
+// cells with the image, so the image moves with the text around it.
+type Kitty struct {
+	w io.Writer
+
+	mu sync.Mutex
+	// ids holds the elements of recent, which is ordered from the most to the
+	// least recently drawn image.
+	ids    map[imageKey]*list.Element
+	recent *list.List
+	nextID uint32
+}
+
+// NewKitty returns a Kitty that writes graphics commands to w, which must be
+// the terminal.
+func NewKitty(w io.Writer) *Kitty {
+	return &Kitty{w: w, ids: make(map[imageKey]*list.Element), recent: list.New(), nextID: 1}
+}
+
+// Lines returns the placeholder cells of img at cols×rows, sending the image
+// to the terminal the first time it is drawn at that size.
+func (k *Kitty) Lines(url string, img image.Image, cols, rows int) []tview.Line {
+	rows = min(rows, KittyMaxRows)
+	id, err := k.transmit(imageKey{url, cols, rows}, img)
+	if err != nil {
+		slog.Error("failed to send image to terminal", "err", err, "url", url)
+		return nil
+	}
+
+	// The image ID is encoded in the foreground colour of the cells. Only
+	// the first cell of a row carries diacritics; the terminal infers the
+	// column of the following cells.
+	style := tcell.StyleDefault.Foreground(tcell.NewHexColor(int32(id)))
+	rest := strings.Repeat(kittyPlaceholder, cols-1)
+	builder := tview.NewLineBuilder()
+	for row := range rows {
+		if row > 0 {
+			builder.NewLine()
+		}
+		first := kittyPlaceholder + string(kittyDiacritics[row]) + string(kittyDiacritics[0])
+		builder.Write(first+rest, style)
+	}
+	return builder.Finish()
+}
+
+func (k *Kitty) transmit(key imageKey, img image.Image) (uint32, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if elem, ok := k.ids[key]; ok {
+		k.recent.MoveToFront(elem)
+		return elem.Value.(kittyImage).id, nil
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return 0, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	// IDs are kept below 2^24 to fit in a 24-bit colour.
+	id := k.nextID
+	k.nextID = k.nextID%0xffffff + 1
+
+	// a=T transmits and displays, U=1 creates a virtual placement for
+	// placeholders and q=2 suppresses replies that would otherwise arrive as
+	// input.
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	header := fmt.Sprintf("a=T,U=1,f=100,t=d,q=2,i=%d,c=%d,r=%d,", id, key.cols, key.rows)
+	for len(data) > 0 {
+		chunk := data[:min(kittyChunkSize, len(data))]
+		data = data[len(chunk):]
+		more := 0
+		if len(data) > 0 {
+			more = 1
+		}
+
+		// Every chunk is written as one complete escape sequence so output
+		// from the screen cannot split it.
+		if _, err := fmt.Fprintf(k.w, "\x1b_G%sm=%d;%s\x1b\\", header, more, chunk); err != nil {
+			return 0, err
+		}
+		header = ""
+	}
+
+	k.ids[key] = k.recent.PushFront(kittyImage{key, id})
+	if k.recent.Len() > kittyMaxImages {
+		oldest := k.recent.Remove(k.recent.Back()).(kittyImage)
+		delete(k.ids, oldest.key)
+		// d=I deletes the image data along with its placements.
+		if _, err := fmt.Fprintf(k.w, "\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", oldest.id); err != nil {
+			slog.Error("failed to delete image from terminal", "err", err, "url", oldest.key.url)
+		}
+	}
+	return id, nil
+}
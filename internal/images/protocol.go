@@ -0,0 +1,49 @@
+// Package images downloads image thumbnails and renders them as terminal
+// cells, so previews scroll, wrap and redraw like any other text.
+package images
+
+import "strings"
+
+type Protocol string
+
+const (
+	// ProtocolAuto picks the best protocol the terminal supports.
+	ProtocolAuto Protocol = "auto"
+	// ProtocolKitty uses the kitty graphics protocol with Unicode
+	// placeholders: the image is sent to the terminal once and drawn in
+	// placeholder cells.
+	ProtocolKitty Protocol = "kitty"
+	// ProtocolSixel paints sixel images over placeholder cells after every
+	// frame that moves them.
+	ProtocolSixel Protocol = "sixel"
+	// ProtocolHalfBlocks draws two pixels per cell with "▀" and works in any
+	// terminal with colour support.
+	ProtocolHalfBlocks Protocol = "halfblocks"
+)
+
+// Detect resolves ProtocolAuto from the environment.
+func Detect(protocol Protocol, getenv func(string) string) Protocol {
+	if protocol != ProtocolAuto {
+		return protocol
+	}
+
+	// tmux passes neither placeholders' image data nor sixels through by
+	// default.
+	if getenv("TMUX") != "" {
+		return ProtocolHalfBlocks
+	}
+
+	term := getenv("TERM")
+	termProgram := getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", strings.Contains(term, "kitty"):
+		return ProtocolKitty
+	case termProgram == "ghostty", strings.Contains(term, "ghostty"):
+		return ProtocolKitty
+	case termProgram == "WezTerm", termProgram == "iTerm.app", getenv("WT_SESSION") != "":
+		return ProtocolSixel
+	case strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "mlterm"), strings.HasPrefix(term, "contour"):
+		return ProtocolSixel
+	}
+	return ProtocolHalfBlocks
+}
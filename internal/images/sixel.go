@@ -0,0 +1,360 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/ayn2op/tview"
+	"github.com/gdamore/tcell/v3"
+)
+
+const (
+	// sixelPlaceholder fills the cells an image is painted over. It is blank
+	// but, unlike a space, is never dropped by word wrapping.
+	sixelPlaceholder = "\u2800"
+	// The colour of a placeholder cell is sixelMarker with the image ID and
+	// the row of the cell in the image in its low bits.
+	sixelMarker  = 0x800000
+	sixelRowBits = 6
+	sixelMaxID   = 1<<(23-sixelRowBits) - 1
+	// sixelColors is the size of the palette: a 6×6×6 colour cube.
+	sixelColors = 216
+)
+
+// SixelMaxRows is the tallest image whose rows can be told apart.
+const SixelMaxRows = 1 << sixelRowBits
+
+// sixelPlacement is the part of an image visible on the screen: rows
+// firstRow up to firstRow+rows of the image, drawn from cell x, y.
+type sixelPlacement struct {
+	x, y           int
+	firstRow, rows int
+}
+
+// sixelAnchor tells apart copies of the same image on the screen by the cell
+// where the first row of the image is, or would be if it were visible.
+type sixelAnchor struct {
+	id     uint32
+	x, top int
+}
+
+// Sixel draws images with sixel graphics. Sixel images are painted over the
+// screen at the cursor rather than in cells, so Lines fills the cells of the
+// image with placeholders that carry the image and row in their colour. Place
+// finds the placeholders once the frame is drawn, paints the visible rows of
+// every image over them and locks the cells so the screen does not draw over
+// the image.
+type Sixel struct {
+	cache *Cache
+
+	mu     sync.Mutex
+	ids    map[imageKey]uint32
+	keys   map[uint32]imageKey
+	nextID uint32
+	// unsupported is set once the terminal does not report its cell size.
+	unsupported bool
+
+	// placed holds the images painted in the last frame, and scaled their
+	// copies at the cell size of that frame.
+	placed        map[sixelAnchor]sixelPlacement
+	scaled        map[uint32]*image.RGBA
+	width, height int
+	cellW, cellH  int
+}
+
+// NewSixel returns a Sixel that paints the images of the cache.
+func NewSixel(cache *Cache) *Sixel {
+	return &Sixel{
+		cache:  cache,
+		ids:    make(map[imageKey]uint32),
+		keys:   make(map[uint32]imageKey),
+		nextID: 1,
+		placed: make(map[sixelAnchor]sixelPlacement),
+		scaled: make(map[uint32]*image.RGBA),
+	}
+}
+
+// Supported reports whether the terminal reports the pixel size of its
+// cells, without which images cannot be sized. It is true until the first
+// frame is placed.
+func (s *Sixel) Supported() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.unsupported
+}
+
+// Lines returns the placeholder cells of the image at url at cols×rows.
+func (s *Sixel) Lines(url string, cols, rows int) []tview.Line {
+	rows = min(rows, SixelMaxRows)
+	id := s.id(imageKey{url, cols, rows})
+
+	placeholders := strings.Repeat(sixelPlaceholder, cols)
+	builder := tview.NewLineBuilder()
+	for row := range rows {
+		if row > 0 {
+			builder.NewLine()
+		}
+		marker := sixelMarker | int32(id)<<sixelRowBits | int32(row)
+		builder.Write(placeholders, tcell.StyleDefault.Foreground(tcell.NewHexColor(marker)))
+	}
+	return builder.Finish()
+}
+
+func (s *Sixel) id(key imageKey) uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.ids[key]; ok {
+		return id
+	}
+
+	// IDs wrap around to fit in the marker; the oldest image gives its ID up.
+	id := s.nextID
+	s.nextID = s.nextID%sixelMaxID + 1
+	if old, ok := s.keys[id]; ok {
+		delete(s.ids, old)
+		delete(s.scaled, id)
+	}
+	s.ids[key] = id
+	s.keys[id] = key
+	return id
+}
+
+// marker returns the image and row of a placeholder cell.
+func (s *Sixel) marker(str string, style tcell.Style) (uint32, int, bool) {
+	fg := style.GetForeground()
+	if str != sixelPlaceholder || !fg.IsRGB() || fg.Hex()&sixelMarker == 0 {
+		return 0, 0, false
+	}
+
+	value := uint32(fg.Hex() &^ sixelMarker)
+	id := value >> sixelRowBits
+	if _, ok := s.keys[id]; !ok {
+		return 0, 0, false
+	}
+	return id, int(value & (SixelMaxRows - 1)), true
+}
+
+// Place paints the images whose placeholders are on the screen. It must be
+// called once the rest of the frame is drawn: images partly covered by other
+// models, such as modals, are left out.
+func (s *Sixel) Place(screen tcell.Screen) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tty, ok := screen.Tty()
+	if !ok {
+		return
+	}
+	windowSize, err := tty.WindowSize()
+	if err != nil {
+		slog.Error("failed to get terminal size", "err", err)
+		return
+	}
+	cellW, cellH := windowSize.CellDimensions()
+	if cellW == 0 || cellH == 0 {
+		if !s.unsupported {
+			slog.Warn("terminal does not report its cell size; falling back to half blocks")
+			s.unsupported = true
+		}
+		return
+	}
+
+	// Resizing clears the screen and rescales the images.
+	width, height := screen.Size()
+	resized := width != s.width || height != s.height || cellW != s.cellW || cellH != s.cellH
+	if resized {
+		s.width, s.height = width, height
+		s.cellW, s.cellH = cellW, cellH
+		clear(s.scaled)
+	}
+
+	placed := s.scan(screen)
+	for anchor, old := range s.placed {
+		// Unlocking marks the cells dirty, so the screen draws over the old
+		// image.
+		if p, ok := placed[anchor]; resized || !ok || p != old {
+			screen.LockRegion(old.x, old.y, s.keys[anchor.id].cols, old.rows, false)
+		}
+	}
+
+	used := make(map[uint32]struct{}, len(placed))
+	for anchor, p := range placed {
+		used[anchor.id] = struct{}{}
+		if old, ok := s.placed[anchor]; ok && old == p && !resized {
+			continue
+		}
+
+		data, ok := s.encode(anchor.id, p)
+		if !ok {
+			delete(placed, anchor)
+			continue
+		}
+		// The cursor is saved and restored around the image, so that the
+		// screen finds it where it left it.
+		if _, err := fmt.Fprintf(tty, "\x1b7\x1b[%d;%dH%s\x1b8", p.y+1, p.x+1, data); err != nil {
+			slog.Error("failed to send image to terminal", "err", err)
+			delete(placed, anchor)
+			continue
+		}
+		screen.LockRegion(p.x, p.y, s.keys[anchor.id].cols, p.rows, true)
+	}
+	s.placed = placed
+
+	// Only the images on the screen keep their scaled copies.
+	for id := range s.scaled {
+		if _, ok := used[id]; !ok {
+			delete(s.scaled, id)
+		}
+	}
+}
+
+// scan finds the visible rows of every image on the screen. Images whose rows
+// are not whole and in order are left out.
+func (s *Sixel) scan(screen tcell.Screen) map[sixelAnchor]sixelPlacement {
+	placed := make(map[sixelAnchor]sixelPlacement)
+	broken := make(map[sixelAnchor]struct{})
+	for y := range s.height {
+		for x := 0; x < s.width; {
+			str, style, width := screen.Get(x, y)
+			id, row, ok := s.marker(str, style)
+			if !ok {
+				x += max(width, 1)
+				continue
+			}
+
+			start := x
+			for x++; x < s.width; x++ {
+				str, style, _ := screen.Get(x, y)
+				if nextID, nextRow, ok := s.marker(str, style); !ok || nextID != id || nextRow != row {
+					break
+				}
+			}
+
+			anchor := sixelAnchor{id, start, y - row}
+			p, ok := placed[anchor]
+			switch {
+			case x-start != s.keys[id].cols:
+				broken[anchor] = struct{}{}
+			case !ok:
+				placed[anchor] = sixelPlacement{x: start, y: y, firstRow: row, rows: 1}
+			case y == p.y+p.rows && row == p.firstRow+p.rows:
+				p.rows++
+				placed[anchor] = p
+			default:
+				broken[anchor] = struct{}{}
+			}
+		}
+	}
+
+	for anchor := range broken {
+		delete(placed, anchor)
+	}
+	return placed
+}
+
+// encode returns the sixel data of the visible rows of the image. Images that
+// are not loaded are fetched and left out until they are.
+func (s *Sixel) encode(id uint32, p sixelPlacement) ([]byte, bool) {
+	key := s.keys[id]
+	scaled, ok := s.scaled[id]
+	if !ok {
+		img, ok := s.cache.Get(key.url)
+		if !ok {
+			return nil, false
+		}
+		scaled = scale(img, key.cols*s.cellW, key.rows*s.cellH)
+		s.scaled[id] = scaled
+	}
+
+	visible := image.Rect(0, p.firstRow*s.cellH, key.cols*s.cellW, (p.firstRow+p.rows)*s.cellH)
+	return encodeSixel(scaled.SubImage(visible).(*image.RGBA)), true
+}
+
+// encodeSixel encodes the image with a 6×6×6 colour cube. Transparent pixels
+// are left unpainted.
+func encodeSixel(img *image.RGBA) []byte {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var buf bytes.Buffer
+	// P2=1 leaves the pixels that are not painted as they are.
+	fmt.Fprintf(&buf, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i := range sixelColors {
+		fmt.Fprintf(&buf, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+
+	colors := make([]int, width*height)
+	for y := range height {
+		for x := range width {
+			c := img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			if c.A < 0x80 {
+				colors[y*width+x] = -1
+				continue
+			}
+			colors[y*width+x] = (int(c.R)*5+127)/255*36 + (int(c.G)*5+127)/255*6 + (int(c.B)*5+127)/255
+		}
+	}
+
+	sixels := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		var used [sixelColors]bool
+		for y := band; y < min(band+6, height); y++ {
+			for _, c := range colors[y*width : (y+1)*width] {
+				if c >= 0 {
+					used[c] = true
+				}
+			}
+		}
+
+		first := true
+		for c := range sixelColors {
+			if !used[c] {
+				continue
+			}
+
+			for x := range width {
+				var bits byte
+				for bit := range min(6, height-band) {
+					if colors[(band+bit)*width+x] == c {
+						bits |= 1 << bit
+					}
+				}
+				sixels[x] = '?' + bits
+			}
+
+			// Every colour after the first starts over at the left of the
+			// band.
+			if !first {
+				buf.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&buf, "#%d", c)
+			writeSixels(&buf, bytes.TrimRight(sixels, "?"))
+		}
+		buf.WriteByte('-')
+	}
+
+	buf.WriteString("\x1b\\")
+	return buf.Bytes()
+}
+
+// writeSixels writes the sixels, compressing runs of the same sixel.
+func writeSixels(buf *bytes.Buffer, sixels []byte) {
+	for len(sixels) > 0 {
+		n := 1
+		for n < len(sixels) && sixels[n] == sixels[0] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(buf, "!%d%c", n, sixels[0])
+		} else {
+			buf.Write(sixels[:n])
+		}
+		sixels = sixels[n:]
+	}
+}
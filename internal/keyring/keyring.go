@@ -10,6 +10,10 @@ const (
 	keyringUser    = "token"
 )
 
+// TokenEnvVarKey is the environment variable whose token is used instead of
+// the one in the keyring.
+const TokenEnvVarKey = "DISCORDO_TOKEN"
+
 func GetToken() (string, error) {
 	return keyring.Get(keyringService, keyringUser)
 }
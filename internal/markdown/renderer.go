@@ -3,6 +3,7 @@ package markdown
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/lexers"
@@ -16,6 +17,8 @@ import (
 
 type Renderer struct {
 	cfg *config.Config
+	// maskSpoilers replaces spoilers with a placeholder.
+	maskSpoilers bool
 
 	listIx     *int
 	listNested int
@@ -24,7 +27,14 @@ type Renderer struct {
 const codeBlockIndent = "    "
 
 func NewRenderer(cfg *config.Config) *Renderer {
-	return &Renderer{cfg: cfg}
+	return &Renderer{cfg: cfg, maskSpoilers: cfg.Markdown.MaskSpoilers}
+}
+
+// SetMaskSpoilers sets whether the next documents are rendered with their
+// spoilers replaced by a placeholder, e.g. once a message's spoilers are
+// revealed.
+func (r *Renderer) SetMaskSpoilers(mask bool) {
+	r.maskSpoilers = mask
 }
 
 func (r *Renderer) RenderLines(source []byte, node ast.Node, base tcell.Style) []tview.Line {
@@ -54,10 +64,23 @@ func (r *Renderer) RenderLines(source []byte, node ast.Node, base tcell.Style) [
 			// noop
 		case *ast.Heading:
 			if entering {
+				pushStyle(tview.MergeStyle(currentStyle(), r.headingStyle(node.Level)))
 				builder.Write(strings.Repeat("#", node.Level)+" ", currentStyle())
 			} else {
+				popStyle()
 				builder.NewLine()
 			}
+		case *ast.Blockquote:
+			if entering {
+				r.renderBlockquote(builder, source, node, currentStyle())
+			}
+			return ast.WalkSkipChildren, nil
+		case *Subtext:
+			if entering {
+				pushStyle(tview.MergeStyle(currentStyle(), theme.SubtextStyle.Style))
+			} else {
+				popStyle()
+			}
 		case *ast.Text:
 			if entering {
 				builder.Write(string(node.Segment.Value(source)), currentStyle())
@@ -90,6 +113,11 @@ func (r *Renderer) RenderLines(source []byte, node ast.Node, base tcell.Style) [
 					linkDepth--
 				}
 				popStyle()
+				// Show where masked links lead, so that a label cannot pass
+				// for another site.
+				if domain := linkDomain(string(node.Destination), linkLabel(node, source)); domain != "" {
+					builder.Write(" ("+domain+")", currentStyle().Dim(true))
+				}
 			}
 		case *ast.List:
 			if node.IsOrdered() {
@@ -118,7 +146,7 @@ func (r *Renderer) RenderLines(source []byte, node ast.Node, base tcell.Style) [
 				builder.NewLine()
 			}
 		case *discordmd.Inline:
-			if r.cfg.Markdown.MaskSpoilers && node.Attr&discordmd.AttrSpoiler != 0 {
+			if r.maskSpoilers && node.Attr&discordmd.AttrSpoiler != 0 {
 				if entering {
 					builder.Write("[spoiler]", currentStyle())
 				}
@@ -137,6 +165,10 @@ func (r *Renderer) RenderLines(source []byte, node ast.Node, base tcell.Style) [
 			if entering {
 				builder.Write(":"+node.Name+":", tview.MergeStyle(currentStyle(), theme.EmojiStyle.Style))
 			}
+		case *Timestamp:
+			if entering {
+				builder.Write(node.Format(time.Now()), tview.MergeStyle(currentStyle(), theme.TimestampStyle.Style))
+			}
 		}
 		return ast.WalkContinue, nil
 	})
@@ -144,6 +176,46 @@ func (r *Renderer) RenderLines(source []byte, node ast.Node, base tcell.Style) [
 	return builder.Finish()
 }
 
+func (r *Renderer) headingStyle(level int) tcell.Style {
+	theme := r.cfg.Theme.MessagesList
+	switch level {
+	case 1:
+		return theme.Heading1Style.Style
+	case 2:
+		return theme.Heading2Style.Style
+	default:
+		return theme.Heading3Style.Style
+	}
+}
+
+// renderBlockquote draws the blocks of the quote with the quote indicator in
+// front of every line.
+func (r *Renderer) renderBlockquote(builder *tview.LineBuilder, source []byte, node *ast.Blockquote, base tcell.Style) {
+	// Lists inside the quote must not reset the numbering of the lists
+	// around it.
+	listIx, listNested := r.listIx, r.listNested
+	defer func() { r.listIx, r.listNested = listIx, listNested }()
+
+	var lines []tview.Line
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		lines = append(lines, r.RenderLines(source, child, base)...)
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	theme := r.cfg.Theme.MessagesList
+	indicator := tview.NewSegment(theme.QuoteIndicator, tview.MergeStyle(base, theme.QuoteStyle.Style))
+	for i, line := range lines {
+		lines[i] = append(tview.Line{indicator}, line...)
+	}
+
+	if builder.HasCurrentLine() {
+		builder.NewLine()
+	}
+	builder.AppendLines(lines)
+}
+
 func (r *Renderer) renderFencedCodeBlock(builder *tview.LineBuilder, source []byte, node *ast.FencedCodeBlock, base tcell.Style) {
 	var code strings.Builder
 	lines := node.Lines()
@@ -0,0 +1,284 @@
+package markdown
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	multilineQuotePrefix = ">>> "
+	subtextPrefix        = "-# "
+)
+
+// KindSubtext is the kind of Subtext nodes.
+var KindSubtext = ast.NewNodeKind("Subtext")
+
+// Subtext is a line written after "-# ", drawn smaller in Discord and dimmed
+// here.
+type Subtext struct {
+	ast.BaseInline
+}
+
+func (n *Subtext) Kind() ast.NodeKind {
+	return KindSubtext
+}
+
+func (n *Subtext) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// ExpandQuotes rewrites a ">>> " quote, which runs to the end of the message
+// in Discord, as a quote of every line that follows it. Lines in fenced code
+// blocks are not quotes. It returns the source unchanged when there is none.
+func ExpandQuotes(source []byte) []byte {
+	start := 0
+	var fenceChar byte
+	var fenceLen int
+	for {
+		line := source[start:]
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+		}
+
+		char, n, info := codeFence(line)
+		switch {
+		case fenceLen > 0:
+			// A closing fence is at least as long as the opening one and has
+			// nothing after it.
+			if char == fenceChar && n >= fenceLen && len(bytes.TrimSpace(info)) == 0 {
+				fenceLen = 0
+			}
+		case n > 0:
+			fenceChar, fenceLen = char, n
+		case bytes.HasPrefix(line, []byte(multilineQuotePrefix)):
+			return expandQuote(source, start)
+		}
+
+		if start+len(line) == len(source) {
+			return source
+		}
+		start += len(line) + 1
+	}
+}
+
+// expandQuote quotes every line from the ">>> " quote at start.
+func expandQuote(source []byte, start int) []byte {
+	var b bytes.Buffer
+	b.Grow(len(source) + len(source)/8)
+	b.Write(source[:start])
+	rest := source[start+len(multilineQuotePrefix):]
+	for i, line := range bytes.Split(rest, []byte("\n")) {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("> ")
+		b.Write(line)
+	}
+	return b.Bytes()
+}
+
+// codeFence returns the fence character and length of a line that opens or
+// closes a fenced code block, and what follows the fence. The length is 0 for
+// other lines.
+func codeFence(line []byte) (byte, int, []byte) {
+	trimmed := bytes.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) == 0 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return 0, 0, nil
+	}
+
+	char := trimmed[0]
+	n := 0
+	for n < len(trimmed) && trimmed[n] == char {
+		n++
+	}
+	info := trimmed[n:]
+	// The info string of a backtick fence cannot contain backticks, or the
+	// line would start with inline code.
+	if n < 3 || (char == '`' && bytes.IndexByte(info, '`') >= 0) {
+		return 0, 0, nil
+	}
+	return char, n, info
+}
+
+// Transform applies the Discord syntax the parser leaves as text: timestamps,
+// subtext, and quotes that end with their line.
+func Transform(node ast.Node, source []byte) {
+	ExpandTimestamps(node, source)
+	splitLazyQuotes(node, source)
+	expandSubtext(node, source)
+}
+
+type transformer struct{}
+
+// Transformer applies Transform to documents parsed with it.
+var Transformer parser.ASTTransformer = transformer{}
+
+func (transformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	Transform(doc, reader.Source())
+}
+
+// splitLazyQuotes moves the lines that continue a quoted paragraph without a
+// ">" out of the quote. CommonMark treats them as part of the quote, whereas
+// Discord quotes only the lines starting with ">".
+func splitLazyQuotes(node ast.Node, source []byte) {
+	var quotes []*ast.Blockquote
+	_ = ast.Walk(node, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if quote, ok := node.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, quote)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, quote := range quotes {
+		paragraph, ok := quote.LastChild().(*ast.Paragraph)
+		if !ok {
+			continue
+		}
+
+		// Split the paragraph into runs of lines that are all quoted or all
+		// not quoted.
+		type run struct {
+			quoted bool
+			nodes  []ast.Node
+		}
+		var runs []run
+		lineStart := true
+		for child := paragraph.FirstChild(); child != nil; child = child.NextSibling() {
+			if lineStart {
+				if start := textStart(child); start >= 0 {
+					quoted := quotedLine(source, start)
+					if len(runs) == 0 || runs[len(runs)-1].quoted != quoted {
+						runs = append(runs, run{quoted: quoted})
+					}
+				}
+			}
+			if len(runs) > 0 {
+				runs[len(runs)-1].nodes = append(runs[len(runs)-1].nodes, child)
+			}
+			t, ok := child.(*ast.Text)
+			lineStart = ok && (t.SoftLineBreak() || t.HardLineBreak())
+		}
+		if len(runs) < 2 {
+			continue
+		}
+
+		parent, after := quote.Parent(), ast.Node(quote)
+		for i, run := range runs {
+			// The last line of every run ends its block.
+			if t, ok := run.nodes[len(run.nodes)-1].(*ast.Text); ok {
+				t.SetSoftLineBreak(false)
+			}
+			if i == 0 {
+				continue
+			}
+
+			block := ast.NewParagraph()
+			for _, node := range run.nodes {
+				paragraph.RemoveChild(paragraph, node)
+				block.AppendChild(block, node)
+			}
+			var next ast.Node = block
+			if run.quoted {
+				next = ast.NewBlockquote()
+				next.AppendChild(next, block)
+			}
+			parent.InsertAfter(parent, after, next)
+			after = next
+		}
+	}
+}
+
+// textStart returns the offset of the first text of the node, or -1.
+func textStart(node ast.Node) int {
+	start := -1
+	_ = ast.Walk(node, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := node.(*ast.Text); ok && entering {
+			start = t.Segment.Start
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return start
+}
+
+// quotedLine reports whether the line holding offset starts with ">".
+func quotedLine(source []byte, offset int) bool {
+	start := bytes.LastIndexByte(source[:offset], '\n') + 1
+	return bytes.HasPrefix(bytes.TrimLeft(source[start:offset], " "), []byte(">"))
+}
+
+// expandSubtext wraps the lines starting with "-# " in Subtext nodes.
+func expandSubtext(node ast.Node, source []byte) {
+	var paragraphs []*ast.Paragraph
+	_ = ast.Walk(node, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if paragraph, ok := node.(*ast.Paragraph); ok && entering {
+			paragraphs = append(paragraphs, paragraph)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, paragraph := range paragraphs {
+		lineStart := true
+		for child := paragraph.FirstChild(); child != nil; child = child.NextSibling() {
+			t, ok := child.(*ast.Text)
+			if !lineStart || !ok {
+				lineStart = ok && (t.SoftLineBreak() || t.HardLineBreak())
+				continue
+			}
+
+			mergeText(t)
+			if !bytes.HasPrefix(t.Segment.Value(source), []byte(subtextPrefix)) {
+				lineStart = t.SoftLineBreak() || t.HardLineBreak()
+				continue
+			}
+			t.Segment = t.Segment.WithStart(t.Segment.Start + len(subtextPrefix))
+
+			subtext := &Subtext{}
+			paragraph.InsertBefore(paragraph, t, subtext)
+			var node ast.Node = t
+			for node != nil {
+				next := node.NextSibling()
+				paragraph.RemoveChild(paragraph, node)
+				subtext.AppendChild(subtext, node)
+				if t, ok := node.(*ast.Text); ok && (t.SoftLineBreak() || t.HardLineBreak()) {
+					break
+				}
+				node = next
+			}
+			child = subtext
+			lineStart = true
+		}
+	}
+}
+
+// linkDomain returns the host of the link, or "" when the label already
+// shows it.
+func linkDomain(destination, label string) string {
+	u, err := url.Parse(destination)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	label = strings.TrimSpace(label)
+	if label == destination || strings.EqualFold(label, u.Host) {
+		return ""
+	}
+	return u.Host
+}
+
+// linkLabel returns the text of the label of the link.
+func linkLabel(node *ast.Link, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(node, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := node.(*ast.Text); ok && entering {
+			b.Write(t.Segment.Value(source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/gdamore/tcell/v3"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+func renderText(t *testing.T, cfg *config.Config, source []byte) []string {
+	t.Helper()
+	p := goldmark.DefaultParser()
+	p.AddOptions(parser.WithASTTransformers(util.Prioritized(Transformer, 100)))
+	node := p.Parse(text.NewReader(source))
+
+	var lines []string
+	for _, line := range NewRenderer(cfg).RenderLines(source, node, tcell.StyleDefault) {
+		var b strings.Builder
+		for _, segment := range line {
+			b.WriteString(segment.Text)
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+func TestExpandQuotes(t *testing.T) {
+	for _, test := range []struct{ source, want string }{
+		{"no quote", "no quote"},
+		{">>> a\nb", "> a\n> b"},
+		{"before\n>>> a\nb", "before\n> a\n> b"},
+		{"not >>> a quote", "not >>> a quote"},
+		{"```py\n>>> x\n```", "```py\n>>> x\n```"},
+		{"~~~\n>>> x\n~~~", "~~~\n>>> x\n~~~"},
+		{"````\n```\n>>> x\n````", "````\n```\n>>> x\n````"},
+		{"```py\n>>> x\n```\n>>> a\nb", "```py\n>>> x\n```\n> a\n> b"},
+		{"`>>> x`", "`>>> x`"},
+		{"``` `code` ```\n>>> a", "``` `code` ```\n> a"},
+		{">>> ```py\n>>> x\n```", "> ```py\n> >>> x\n> ```"},
+	} {
+		if got := string(ExpandQuotes([]byte(test.source))); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestRendererQuotes(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Theme.MessagesList.QuoteIndicator = "| "
+
+	got := renderText(t, cfg, []byte("> quoted\nnot quoted"))
+	want := []string{"| quoted", "not quoted"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRendererSubtext(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Theme.MessagesList.SubtextStyle.Style = tcell.StyleDefault.Dim(true)
+
+	source := []byte("-# small print")
+	p := goldmark.DefaultParser()
+	p.AddOptions(parser.WithASTTransformers(util.Prioritized(Transformer, 100)))
+	node := p.Parse(text.NewReader(source))
+	lines := NewRenderer(cfg).RenderLines(source, node, tcell.StyleDefault)
+	if len(lines) == 0 || len(lines[0]) == 0 {
+		t.Fatal("nothing rendered")
+	}
+	segment := lines[0][0]
+	if segment.Text != "small print" || !segment.Style.HasDim() {
+		t.Fatalf("got %q dim=%t, want dimmed %q", segment.Text, segment.Style.HasDim(), "small print")
+	}
+}
+
+func TestRendererMaskedLinks(t *testing.T) {
+	for _, test := range []struct{ source, want string }{
+		{"[free nitro](https://evil.example/claim)", "free nitro (evil.example)"},
+		{"[discord.com](https://discord.com)", "discord.com"},
+		{"<https://discord.com>", "https://discord.com"},
+	} {
+		got := renderText(t, &config.Config{}, []byte(test.source))
+		if strings.Join(got, "\n") != test.want {
+			t.Errorf("%q: got %q, want %q", test.source, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/ayn2op/ningen/v3/discordmd"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Timestamp styles, as written after the time in the markup.
+const (
+	ShortTime     = 't'
+	LongTime      = 'T'
+	ShortDate     = 'd'
+	LongDate      = 'D'
+	ShortDateTime = 'f'
+	LongDateTime  = 'F'
+	Relative      = 'R'
+)
+
+// timestampRegex matches <t:unix> and <t:unix:style>.
+var timestampRegex = regexp.MustCompile(`<t:(-?\d{1,13})(?::([tTdDfFR]))?>`)
+
+// KindTimestamp is the kind of Timestamp nodes.
+var KindTimestamp = ast.NewNodeKind("Timestamp")
+
+// Timestamp is a time written as <t:unix:style>, shown in the reader's local
+// time.
+type Timestamp struct {
+	ast.BaseInline
+	Time  time.Time
+	Style byte
+}
+
+func (n *Timestamp) Kind() ast.NodeKind {
+	return KindTimestamp
+}
+
+func (n *Timestamp) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Time":  n.Time.String(),
+		"Style": string(n.Style),
+	}, nil)
+}
+
+// Format returns the time as Discord shows it in the style of the node;
+// relative times are measured from now.
+func (n *Timestamp) Format(now time.Time) string {
+	t := n.Time.Local()
+	switch n.Style {
+	case ShortTime:
+		return t.Format("3:04 PM")
+	case LongTime:
+		return t.Format("3:04:05 PM")
+	case ShortDate:
+		return t.Format("01/02/2006")
+	case LongDate:
+		return t.Format("January 2, 2006")
+	case LongDateTime:
+		return t.Format("Monday, January 2, 2006 3:04 PM")
+	case Relative:
+		return relativeTime(n.Time, now)
+	default:
+		return t.Format("January 2, 2006 3:04 PM")
+	}
+}
+
+// relativeTime rounds the distance between t and now the way Discord does,
+// e.g. "in 5 minutes" or "a year ago".
+func relativeTime(t, now time.Time) string {
+	d := t.Sub(now)
+	future := d > 0
+	if !future {
+		d = -d
+	}
+
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+	var s string
+	switch {
+	case d < 45*time.Second:
+		s = "a few seconds"
+	case d < 90*time.Second:
+		s = "a minute"
+	case d < 45*time.Minute:
+		s = plural(d.Round(time.Minute)/time.Minute, "minutes")
+	case d < 90*time.Minute:
+		s = "an hour"
+	case d < 22*time.Hour:
+		s = plural(d.Round(time.Hour)/time.Hour, "hours")
+	case d < 36*time.Hour:
+		s = "a day"
+	case d < 26*day:
+		s = plural((d+day/2)/day, "days")
+	case d < 45*day:
+		s = "a month"
+	case d < 320*day:
+		s = plural((d+month/2)/month, "months")
+	case d < 548*day:
+		s = "a year"
+	default:
+		s = plural((d+year/2)/year, "years")
+	}
+
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+func plural(n time.Duration, unit string) string {
+	return strconv.FormatInt(int64(n), 10) + " " + unit
+}
+
+// HasRelativeTimestamp reports whether the content has a relative timestamp,
+// whose text changes as time passes.
+func HasRelativeTimestamp(content string) bool {
+	for _, match := range timestampRegex.FindAllStringSubmatch(content, -1) {
+		if match[2] == string(Relative) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandTimestamps replaces the timestamp markup in the text of the document
+// with Timestamp nodes. Code is left as written.
+func ExpandTimestamps(node ast.Node, source []byte) {
+	var texts []*ast.Text
+	monospace := 0
+	_ = ast.Walk(node, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch node := node.(type) {
+		case *discordmd.Inline:
+			if node.Attr&discordmd.AttrMonospace != 0 {
+				if entering {
+					monospace++
+				} else {
+					monospace--
+				}
+			}
+		case *ast.CodeSpan, *ast.FencedCodeBlock, *ast.CodeBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if entering && monospace == 0 {
+				texts = append(texts, node)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, t := range texts {
+		// Merged into the text before it.
+		if t.Parent() == nil {
+			continue
+		}
+		// The markup may have been split into several text nodes at
+		// characters other inline parsers look at.
+		mergeText(t)
+		expandTimestamps(t, source)
+	}
+}
+
+// mergeText joins the text nodes that follow t and continue its segment.
+func mergeText(t *ast.Text) {
+	for {
+		next, ok := t.NextSibling().(*ast.Text)
+		if !ok || t.SoftLineBreak() || t.HardLineBreak() || next.Segment.Start != t.Segment.Stop || next.IsRaw() != t.IsRaw() {
+			return
+		}
+		t.Segment = t.Segment.WithStop(next.Segment.Stop)
+		t.SetSoftLineBreak(next.SoftLineBreak())
+		t.SetHardLineBreak(next.HardLineBreak())
+		next.Parent().RemoveChild(next.Parent(), next)
+	}
+}
+
+func expandTimestamps(t *ast.Text, source []byte) {
+	segment := t.Segment
+	value := segment.Value(source)
+	matches := timestampRegex.FindAllSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return
+	}
+
+	parent := t.Parent()
+	var after ast.Node = t.PreviousSibling()
+	insert := func(node ast.Node) {
+		if after == nil {
+			if first := parent.FirstChild(); first != nil {
+				parent.InsertBefore(parent, first, node)
+			} else {
+				parent.AppendChild(parent, node)
+			}
+		} else {
+			parent.InsertAfter(parent, after, node)
+		}
+		after = node
+	}
+	parent.RemoveChild(parent, t)
+
+	start := 0
+	for _, match := range matches {
+		if match[0] > start {
+			insert(ast.NewTextSegment(text.NewSegment(segment.Start+start, segment.Start+match[0])))
+		}
+
+		// The regex limits the digits, so the number always fits.
+		unix, _ := strconv.ParseInt(string(value[match[2]:match[3]]), 10, 64)
+		style := byte(ShortDateTime)
+		if match[4] >= 0 {
+			style = value[match[4]]
+		}
+		insert(&Timestamp{Time: time.Unix(unix, 0), Style: style})
+		start = match[1]
+	}
+
+	// The remaining text keeps the line break that ended the original node.
+	rest := ast.NewTextSegment(text.NewSegment(segment.Start+start, segment.Stop))
+	rest.SetSoftLineBreak(t.SoftLineBreak())
+	rest.SetHardLineBreak(t.HardLineBreak())
+	if start < len(value) || t.SoftLineBreak() || t.HardLineBreak() {
+		insert(rest)
+	}
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/ningen/v3/discordmd"
+	"github.com/gdamore/tcell/v3"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+func TestRendererTimestamps(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+
+	source := []byte("at <t:1700000000:t>, <t:1700000000:D> and <t:1700000000> but not `<t:1700000000:T>`")
+	node := parser.NewParser(
+		parser.WithBlockParsers(discordmd.BlockParsers()...),
+		parser.WithInlineParsers(discordmd.InlineParserWithLink()...),
+		parser.WithASTTransformers(util.Prioritized(Transformer, 100)),
+	).Parse(text.NewReader(source))
+
+	lines := NewRenderer(&config.Config{}).RenderLines(source, node, tcell.StyleDefault)
+	var got strings.Builder
+	for _, line := range lines {
+		for _, segment := range line {
+			got.WriteString(segment.Text)
+		}
+	}
+	want := "at 10:13 PM, November 14, 2023 and November 14, 2023 10:13 PM but not <t:1700000000:T>"
+	if got.String() != want {
+		t.Fatalf("got %q, want %q", got.String(), want)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for _, test := range []struct {
+		offset time.Duration
+		want   string
+	}{
+		{10 * time.Second, "in a few seconds"},
+		{-time.Minute, "a minute ago"},
+		{5 * time.Minute, "in 5 minutes"},
+		{-3 * time.Hour, "3 hours ago"},
+		{-5 * 24 * time.Hour, "5 days ago"},
+		{100 * 24 * time.Hour, "in 3 months"},
+		{-1000 * 24 * time.Hour, "3 years ago"},
+	} {
+		if got := relativeTime(now.Add(test.offset), now); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.offset, got, test.want)
+		}
+	}
+}
+
+func TestHasRelativeTimestamp(t *testing.T) {
+	if !HasRelativeTimestamp("ends <t:1700000000:R>") {
+		t.Error("relative timestamp not found")
+	}
+	if HasRelativeTimestamp("ends <t:1700000000:F>") {
+		t.Error("absolute timestamp reported as relative")
+	}
+}
@@ -0,0 +1,288 @@
+package chat
+
+import (
+	"slices"
+	"strconv"
+	"unicode"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/help"
+	"github.com/ayn2op/tview/keybind"
+	"github.com/ayn2op/tview/layers"
+)
+
+// auditLog remembers, in memory, the messages deleted and the previous
+// revisions of the messages edited while they were loaded. A nil auditLog
+// remembers nothing.
+type auditLog struct {
+	deleted   map[discord.MessageID]struct{}
+	revisions map[discord.MessageID][]revision
+}
+
+type revision struct {
+	Content   string
+	Timestamp discord.Timestamp
+}
+
+// newAuditLog returns nil unless audit mode is enabled.
+func newAuditLog(cfg *config.Config) *auditLog {
+	if !cfg.AuditMode {
+		return nil
+	}
+	return &auditLog{
+		deleted:   make(map[discord.MessageID]struct{}),
+		revisions: make(map[discord.MessageID][]revision),
+	}
+}
+
+func (a *auditLog) isDeleted(messageID discord.MessageID) bool {
+	if a == nil {
+		return false
+	}
+	_, ok := a.deleted[messageID]
+	return ok
+}
+
+func (a *auditLog) markDeleted(messageID discord.MessageID) {
+	a.deleted[messageID] = struct{}{}
+}
+
+// recordEdit keeps the content of old when the update changed it; updates
+// such as embeds being resolved do not create a revision.
+func (a *auditLog) recordEdit(old, updated discord.Message) {
+	if old.Content == updated.Content {
+		return
+	}
+
+	timestamp := old.Timestamp
+	if old.EditedTimestamp.IsValid() {
+		timestamp = old.EditedTimestamp
+	}
+	a.revisions[old.ID] = append(a.revisions[old.ID], revision{Content: old.Content, Timestamp: timestamp})
+}
+
+// history returns the revisions of the message from oldest to newest,
+// ending with its current content.
+func (a *auditLog) history(message discord.Message) []revision {
+	if a == nil || len(a.revisions[message.ID]) == 0 {
+		return nil
+	}
+
+	current := revision{Content: message.Content, Timestamp: message.EditedTimestamp}
+	return append(slices.Clone(a.revisions[message.ID]), current)
+}
+
+// revisionsView is an overlay showing the edits of a message, each revision
+// diffed against the one before it.
+type revisionsView struct {
+	*tview.TextView
+	cfg  *config.Config
+	chat *Model
+}
+
+var (
+	_ tview.Model = (*revisionsView)(nil)
+	_ help.KeyMap = (*revisionsView)(nil)
+)
+
+func newRevisionsView(cfg *config.Config, chat *Model) *revisionsView {
+	rv := &revisionsView{
+		TextView: tview.NewTextView().
+			SetWrap(true).
+			SetWordWrap(true),
+		cfg:  cfg,
+		chat: chat,
+	}
+	ui.ConfigureBox(rv.Box, &cfg.Theme)
+	ui.UpdateBoxFocus(rv.Box, &cfg.Theme, tview.FocusMsg{})
+	rv.SetTitle("Edit history")
+	return rv
+}
+
+func (rv *revisionsView) setRevisions(revisions []revision) {
+	theme := rv.cfg.Theme.MessagesList
+	baseStyle := theme.MessageStyle.Style
+	addedStyle := tview.MergeStyle(baseStyle, theme.DiffAddedStyle.Style)
+	removedStyle := tview.MergeStyle(baseStyle, theme.DiffRemovedStyle.Style)
+
+	builder := tview.NewLineBuilder()
+	for i, rev := range revisions {
+		if i > 0 {
+			builder.NewLine()
+			builder.NewLine()
+		}
+
+		title := "Revision " + strconv.Itoa(i+1)
+		if i == len(revisions)-1 {
+			title = "Current"
+		}
+		if rev.Timestamp.IsValid() {
+			title += " · " + rev.Timestamp.Time().Local().Format(rv.cfg.Timestamps.Format)
+		}
+		builder.Write(title, baseStyle.Bold(true))
+		builder.NewLine()
+
+		if i == 0 {
+			builder.Write(rev.Content, baseStyle)
+			continue
+		}
+		for _, op := range diffWords(revisions[i-1].Content, rev.Content) {
+			switch op.kind {
+			case diffAdded:
+				builder.Write(op.text, addedStyle)
+			case diffRemoved:
+				builder.Write(op.text, removedStyle)
+			default:
+				builder.Write(op.text, baseStyle)
+			}
+		}
+	}
+	rv.SetLines(builder.Finish())
+}
+
+func (rv *revisionsView) Update(msg tview.Msg) tview.Cmd {
+	if msg, ok := msg.(tview.KeyMsg); ok && keybind.Matches(msg, rv.cfg.Keybinds.Picker.Cancel.Keybind) {
+		return rv.chat.closeRevisions()
+	}
+	return rv.TextView.Update(msg)
+}
+
+func (rv *revisionsView) ShortHelp() []keybind.Keybind {
+	return []keybind.Keybind{rv.cfg.Keybinds.Picker.Cancel.Keybind}
+}
+
+func (rv *revisionsView) FullHelp() [][]keybind.Keybind {
+	return [][]keybind.Keybind{{rv.cfg.Keybinds.Picker.Cancel.Keybind}}
+}
+
+func (ml *messagesList) showRevisions() tview.Cmd {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	revisions := ml.chat.audit.history(*selectedMessage)
+	if len(revisions) == 0 {
+		return ui.ShowModal("No edits of this message were seen.", ui.ModalButton{Label: "Close"})
+	}
+
+	m := ml.chat
+	m.revisionsView.setRevisions(revisions)
+	m.AddLayer(
+		ui.Centered(m.revisionsView, m.cfg.Picker.Width, m.cfg.Picker.Height),
+		layers.WithName(revisionsLayerName),
+		layers.WithResize(true),
+		layers.WithVisible(true),
+		layers.WithOverlay(),
+	).SendToFront(revisionsLayerName)
+	return tview.SetFocus(m.revisionsView)
+}
+
+func (m *Model) closeRevisions() tview.Cmd {
+	m.RemoveLayer(revisionsLayerName)
+	return tview.SetFocus(m.activeMessagesList())
+}
+
+type diffKind uint8
+
+const (
+	diffEqual diffKind = iota
+	diffAdded
+	diffRemoved
+)
+
+type diffOp struct {
+	kind diffKind
+	text string
+}
+
+// diffWords diffs two texts word by word, keeping whitespace, using the
+// longest common subsequence of their words.
+func diffWords(before, after string) []diffOp {
+	a, b := splitWords(before), splitWords(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	push := func(kind diffKind, text string) {
+		if n := len(ops); n > 0 && ops[n-1].kind == kind {
+			ops[n-1].text += text
+			return
+		}
+		ops = append(ops, diffOp{kind: kind, text: text})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			push(diffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			push(diffRemoved, a[i])
+			i++
+		default:
+			push(diffAdded, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		push(diffRemoved, a[i])
+	}
+	for ; j < len(b); j++ {
+		push(diffAdded, b[j])
+	}
+	return ops
+}
+
+// splitWords splits s into runs of non-space and space characters.
+func splitWords(s string) []string {
+	var words []string
+	start, space := 0, false
+	for i, r := range s {
+		if i > start && unicode.IsSpace(r) != space {
+			words = append(words, s[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// isMessageAction reports whether the key acts on the selected message
+// through Discord, which is not possible once the message is deleted.
+func (ml *messagesList) isMessageAction(msg tview.KeyMsg) bool {
+	cfg := ml.cfg.Keybinds.MessagesList
+	return slices.ContainsFunc([]config.Keybind{
+		cfg.Reply, cfg.ReplyMention, cfg.Edit, cfg.Delete, cfg.DeleteConfirm,
+		cfg.AddReaction, cfg.ToggleReaction, cfg.Vote, cfg.RemoveVotes, cfg.TogglePin, cfg.Interact,
+	}, func(kb config.Keybind) bool {
+		return keybind.Matches(msg, kb.Keybind)
+	})
+}
+
+func (ml *messagesList) selectedMessageDeleted() bool {
+	selectedMessage, ok := ml.selectedMessage()
+	return ok && ml.chat.audit.isDeleted(selectedMessage.ID)
+}
@@ -0,0 +1,491 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/api"
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/arikawa/v3/utils/ws"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/discordo/internal/ui/chat/mentionslist"
+	"github.com/ayn2op/tview"
+	"github.com/gdamore/tcell/v3"
+)
+
+// maxCommandSuggestions is how many suggestions are listed when the mentions
+// list has no limit of its own.
+const maxCommandSuggestions = 25
+
+// mentionIDRegex matches the ID in user, role and channel mentions.
+var mentionIDRegex = regexp.MustCompile(`^<(?:@[!&]?|#)(\d+)>$`)
+
+type applicationCommand struct {
+	ID            discord.CommandID   `json:"id"`
+	ApplicationID discord.AppID       `json:"application_id"`
+	Version       discord.Snowflake   `json:"version"`
+	Type          discord.CommandType `json:"type"`
+	Name          string              `json:"name"`
+	Description   string              `json:"description"`
+	Options       []commandOption     `json:"options"`
+
+	// raw is sent back with the invocation, as the official client does.
+	raw json.RawMessage
+}
+
+func (cmd *applicationCommand) UnmarshalJSON(data []byte) error {
+	type plain applicationCommand
+	if err := json.Unmarshal(data, (*plain)(cmd)); err != nil {
+		return err
+	}
+	cmd.raw = slices.Clone(data)
+	return nil
+}
+
+type commandOption struct {
+	Type         discord.CommandOptionType `json:"type"`
+	Name         string                    `json:"name"`
+	Description  string                    `json:"description"`
+	Required     bool                      `json:"required"`
+	Autocomplete bool                      `json:"autocomplete"`
+	Choices      []commandChoice           `json:"choices"`
+	Options      []commandOption           `json:"options"`
+}
+
+type commandChoice struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+func isSubcommand(option commandOption) bool {
+	return option.Type == discord.SubcommandOptionType || option.Type == discord.SubcommandGroupOptionType
+}
+
+// commandOptionValue is an option of an invocation; subcommands hold the
+// options given to them.
+type commandOptionValue struct {
+	Type    discord.CommandOptionType `json:"type"`
+	Name    string                    `json:"name"`
+	Value   any                       `json:"value,omitempty"`
+	Options []commandOptionValue      `json:"options,omitempty"`
+	Focused bool                      `json:"focused,omitempty"`
+}
+
+type applicationCommandData struct {
+	Version            discord.Snowflake    `json:"version"`
+	ID                 discord.CommandID    `json:"id"`
+	Name               string               `json:"name"`
+	Type               discord.CommandType  `json:"type"`
+	Options            []commandOptionValue `json:"options"`
+	ApplicationCommand json.RawMessage      `json:"application_command"`
+	Attachments        []struct{}           `json:"attachments"`
+}
+
+// autocompleteResponseEvent carries the choices an application suggests for
+// the option being typed.
+type autocompleteResponseEvent struct {
+	Nonce   string          `json:"nonce"`
+	Choices []commandChoice `json:"choices"`
+}
+
+func (*autocompleteResponseEvent) Op() ws.OpCode { return dispatchOp }
+func (*autocompleteResponseEvent) EventType() ws.EventType {
+	return "APPLICATION_COMMAND_AUTOCOMPLETE_RESPONSE"
+}
+
+type commandsLoadedMsg struct {
+	composer  *composer
+	channelID discord.ChannelID
+	commands  []applicationCommand
+}
+
+// loadCommands fetches the commands that can be used in the channel.
+func (c *composer) loadCommands(channelID discord.ChannelID) tview.Cmd {
+	return func() tview.Msg {
+		var index struct {
+			Commands []applicationCommand `json:"application_commands"`
+		}
+		url := api.EndpointChannels + channelID.String() + "/application-command-index"
+		if err := c.chat.state.RequestJSON(&index, "GET", url); err != nil {
+			slog.Error("failed to get application commands", "err", err, "channel_id", channelID)
+			return nil
+		}
+
+		commands := slices.DeleteFunc(index.Commands, func(cmd applicationCommand) bool {
+			return cmd.Type != discord.ChatInputCommand
+		})
+		return commandsLoadedMsg{composer: c, channelID: channelID, commands: commands}
+	}
+}
+
+func (c *composer) onCommandsLoaded(msg commandsLoadedMsg) tview.Cmd {
+	c.commands[msg.channelID] = msg.commands
+	if !c.commandMode() {
+		return nil
+	}
+	return c.suggestCommand(false)
+}
+
+// commandInput is a command typed in the composer:
+//
+//	/name [group] [subcommand] option:value option:value
+//
+// Values run until the next known option name, so they may contain spaces.
+type commandInput struct {
+	command *applicationCommand
+	// path holds the subcommand group and subcommand given, if any.
+	path []commandOption
+	// options are the options accepted after path.
+	options []commandOption
+	values  []optionInput
+}
+
+type optionInput struct {
+	option commandOption
+	value  string
+}
+
+func parseCommand(text string, commands []applicationCommand) (commandInput, bool) {
+	rest, ok := strings.CutPrefix(text, "/")
+	if !ok {
+		return commandInput{}, false
+	}
+	tokens := strings.Fields(rest)
+	if len(tokens) == 0 {
+		return commandInput{}, false
+	}
+
+	index := slices.IndexFunc(commands, func(cmd applicationCommand) bool {
+		return cmd.Name == tokens[0]
+	})
+	if index < 0 {
+		return commandInput{}, false
+	}
+
+	input := commandInput{command: &commands[index], options: commands[index].Options}
+	tokens = tokens[1:]
+	for len(tokens) > 0 {
+		i := slices.IndexFunc(input.options, func(option commandOption) bool {
+			return isSubcommand(option) && option.Name == tokens[0]
+		})
+		if i < 0 {
+			break
+		}
+		input.path = append(input.path, input.options[i])
+		input.options = input.options[i].Options
+		tokens = tokens[1:]
+	}
+
+	for _, token := range tokens {
+		if name, value, ok := strings.Cut(token, ":"); ok {
+			if i := slices.IndexFunc(input.options, func(option commandOption) bool {
+				return !isSubcommand(option) && option.Name == name
+			}); i >= 0 {
+				input.values = append(input.values, optionInput{option: input.options[i], value: value})
+				continue
+			}
+		}
+		if n := len(input.values); n > 0 {
+			input.values[n-1].value += " " + token
+		}
+	}
+	return input, true
+}
+
+// needsSubcommand reports whether a subcommand has yet to be given.
+func (input commandInput) needsSubcommand() bool {
+	return slices.ContainsFunc(input.options, isSubcommand)
+}
+
+func (input commandInput) has(name string) bool {
+	return slices.ContainsFunc(input.values, func(value optionInput) bool {
+		return value.option.Name == name
+	})
+}
+
+func (c *composer) commandMode() bool {
+	return strings.HasPrefix(c.Text(), "/")
+}
+
+// suggestCommand lists the commands, subcommands, options or choices that
+// complete the word at the end of the composer. Unless all is set, nothing is
+// listed between options, so that the command can be sent.
+func (c *composer) suggestCommand(all bool) tview.Cmd {
+	selectedChannel, ok := c.messagesList.selectedChannel()
+	if !ok {
+		return nil
+	}
+
+	text := c.Text()
+	commands, loaded := c.commands[selectedChannel.ID]
+	// The commands are fetched again every time a command is started, so
+	// that newly added bots show up.
+	if !loaded || text == "/" {
+		return tview.Batch(c.stopTabCompletion(), c.loadCommands(selectedChannel.ID))
+	}
+
+	c.mentionsList.Clear()
+	c.mentionsList.SetTitle("Commands")
+
+	word := text[strings.LastIndexByte(text, ' ')+1:]
+	if !strings.ContainsRune(text, ' ') {
+		name := strings.TrimPrefix(word, "/")
+		for _, cmd := range commands {
+			if strings.HasPrefix(cmd.Name, name) {
+				c.addCommandSuggestion("/"+cmd.Name+" ", "/"+cmd.Name, cmd.Description)
+			}
+		}
+		return c.showCommandSuggestions()
+	}
+
+	input, ok := parseCommand(text, commands)
+	if !ok || (word == "" && !all && !input.needsSubcommand()) {
+		return c.stopTabCompletion()
+	}
+
+	if name, value, ok := strings.Cut(word, ":"); ok {
+		index := slices.IndexFunc(input.options, func(option commandOption) bool {
+			return option.Name == name
+		})
+		if index >= 0 {
+			for _, choice := range input.options[index].Choices {
+				if strings.Contains(strings.ToLower(choice.Name), strings.ToLower(value)) {
+					c.addCommandSuggestion(name+":"+choiceValue(choice)+" ", choice.Name, "")
+				}
+			}
+		}
+		return c.showCommandSuggestions()
+	}
+
+	for _, option := range input.options {
+		if !strings.HasPrefix(option.Name, word) || input.has(option.Name) {
+			continue
+		}
+		switch {
+		case isSubcommand(option):
+			c.addCommandSuggestion(option.Name+" ", option.Name, option.Description)
+		case input.needsSubcommand():
+		default:
+			display := option.Name + ":"
+			if option.Required {
+				display += " (required)"
+			}
+			c.addCommandSuggestion(option.Name+":", display, option.Description)
+		}
+	}
+	return c.showCommandSuggestions()
+}
+
+func choiceValue(choice commandChoice) string {
+	if s, ok := choice.Value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(choice.Value)
+}
+
+func (c *composer) addCommandSuggestion(insert, name, description string) {
+	limit := int(c.cfg.AutocompleteLimit)
+	if limit == 0 {
+		limit = maxCommandSuggestions
+	}
+	if c.mentionsList.ItemCount() >= limit {
+		return
+	}
+
+	display := name
+	if description != "" {
+		display += " · " + description
+	}
+	c.mentionsList.Append(mentionslist.Item{InsertText: insert, DisplayText: display, Style: tcell.StyleDefault})
+}
+
+func (c *composer) showCommandSuggestions() tview.Cmd {
+	if c.mentionsList.ItemCount() == 0 {
+		return c.stopTabCompletion()
+	}
+	c.mentionsList.Rebuild()
+	return c.showMentionsList()
+}
+
+// completeCommand replaces the word at the end of the composer with the
+// selected suggestion. Completing an option name goes on to its choices.
+func (c *composer) completeCommand() tview.Cmd {
+	insert, ok := c.mentionsList.SelectedInsertText()
+	if !ok {
+		return c.stopTabCompletion()
+	}
+
+	text := c.Text()
+	start := strings.LastIndexByte(text, ' ') + 1
+	c.Replace(start, len(text), insert)
+	if strings.HasSuffix(insert, ":") {
+		return tview.Sequence(c.stopTabCompletion(), c.autocompleteCommand())
+	}
+	return c.suggestCommand(false)
+}
+
+// autocompleteCommand asks the application for the choices of the option
+// being typed, or lists the suggestions when it does not autocomplete.
+func (c *composer) autocompleteCommand() tview.Cmd {
+	selectedChannel, ok := c.messagesList.selectedChannel()
+	if !ok {
+		return nil
+	}
+
+	text := c.Text()
+	input, ok := parseCommand(text, c.commands[selectedChannel.ID])
+	if !ok || len(input.values) == 0 || strings.HasSuffix(text, " ") {
+		return c.suggestCommand(true)
+	}
+	focused := input.values[len(input.values)-1]
+	if !focused.option.Autocomplete {
+		return c.suggestCommand(true)
+	}
+
+	options := make([]commandOptionValue, 0, len(input.values))
+	for _, value := range input.values[:len(input.values)-1] {
+		v, err := c.optionValue(selectedChannel, value)
+		if err != nil {
+			v = value.value
+		}
+		options = append(options, commandOptionValue{Type: value.option.Type, Name: value.option.Name, Value: v})
+	}
+	options = append(options, commandOptionValue{
+		Type:    focused.option.Type,
+		Name:    focused.option.Name,
+		Value:   focused.value,
+		Focused: true,
+	})
+
+	c.autocompleteNonce = newNonce()
+	c.autocompleteOption = focused.option.Name
+	return c.chat.sendInteraction(interactionRequest{
+		Type:          discord.AutocompleteInteractionType,
+		Nonce:         c.autocompleteNonce,
+		ApplicationID: input.command.ApplicationID,
+		GuildID:       selectedChannel.GuildID,
+		ChannelID:     selectedChannel.ID,
+		Data:          input.data(options),
+	})
+}
+
+func (c *composer) onAutocompleteResponse(event *autocompleteResponseEvent) tview.Cmd {
+	if c.autocompleteNonce == "" || event.Nonce != c.autocompleteNonce {
+		return nil
+	}
+	c.autocompleteNonce = ""
+
+	c.mentionsList.Clear()
+	c.mentionsList.SetTitle("Commands")
+	for _, choice := range event.Choices {
+		c.addCommandSuggestion(c.autocompleteOption+":"+choiceValue(choice)+" ", choice.Name, "")
+	}
+	return c.showCommandSuggestions()
+}
+
+// data nests the options in the subcommands given.
+func (input commandInput) data(options []commandOptionValue) applicationCommandData {
+	for i := len(input.path) - 1; i >= 0; i-- {
+		options = []commandOptionValue{{Type: input.path[i].Type, Name: input.path[i].Name, Options: options}}
+	}
+	return applicationCommandData{
+		Version:            input.command.Version,
+		ID:                 input.command.ID,
+		Name:               input.command.Name,
+		Type:               discord.ChatInputCommand,
+		Options:            options,
+		ApplicationCommand: input.command.raw,
+		Attachments:        []struct{}{},
+	}
+}
+
+// sendCommand invokes the command typed in the composer. It reports false
+// when the text is not a command of the channel, in which case it is sent as
+// a message.
+func (c *composer) sendCommand(channel *discord.Channel, text string) (tview.Cmd, bool) {
+	input, ok := parseCommand(text, c.commands[channel.ID])
+	if !ok {
+		return nil, false
+	}
+
+	if input.needsSubcommand() {
+		return ui.ShowModal("Choose a subcommand of /"+input.command.Name+".", ui.ModalButton{Label: "Close"}), true
+	}
+	for _, option := range input.options {
+		if option.Required && !input.has(option.Name) {
+			return ui.ShowModal("/"+input.command.Name+" needs a value for "+option.Name+".", ui.ModalButton{Label: "Close"}), true
+		}
+	}
+
+	options := make([]commandOptionValue, 0, len(input.values))
+	for _, value := range input.values {
+		v, err := c.optionValue(channel, value)
+		if err != nil {
+			return ui.ShowModal(value.option.Name+": "+err.Error(), ui.ModalButton{Label: "Close"}), true
+		}
+		options = append(options, commandOptionValue{Type: value.option.Type, Name: value.option.Name, Value: v})
+	}
+
+	c.typingUntil = time.Time{}
+	c.reset()
+	return c.chat.sendInteraction(interactionRequest{
+		Type:          discord.CommandInteractionType,
+		ApplicationID: input.command.ApplicationID,
+		GuildID:       channel.GuildID,
+		ChannelID:     channel.ID,
+		Data:          input.data(options),
+	}), true
+}
+
+// optionValue converts the typed value to the type of the option. Choices
+// may be given by name or by value, and users, roles and channels by mention
+// or by ID.
+func (c *composer) optionValue(channel *discord.Channel, input optionInput) (any, error) {
+	value := strings.TrimSpace(input.value)
+	for _, choice := range input.option.Choices {
+		if strings.EqualFold(choice.Name, value) {
+			return choice.Value, nil
+		}
+	}
+
+	switch input.option.Type {
+	case discord.IntegerOptionType:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.New("expected a whole number")
+		}
+		return n, nil
+	case discord.NumberOptionType:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("expected a number")
+		}
+		return n, nil
+	case discord.BooleanOptionType:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("expected true or false")
+		}
+		return b, nil
+	case discord.UserOptionType, discord.ChannelOptionType, discord.RoleOptionType, discord.MentionableOptionType:
+		value = string(c.expandMentions(channel, []byte(value)))
+		if match := mentionIDRegex.FindStringSubmatch(value); match != nil {
+			return match[1], nil
+		}
+		if _, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return value, nil
+		}
+		return nil, errors.New("expected a mention or an ID")
+	case discord.AttachmentOptionType:
+		return nil, errors.New("attachment options are not supported yet")
+	default:
+		return value, nil
+	}
+}
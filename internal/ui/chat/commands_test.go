@@ -0,0 +1,180 @@
+package chat
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+)
+
+var testCommands = []applicationCommand{
+	{
+		Name: "echo",
+		Options: []commandOption{
+			{Type: discord.StringOptionType, Name: "text", Required: true},
+			{Type: discord.IntegerOptionType, Name: "count"},
+		},
+	},
+	{
+		Name: "config",
+		Options: []commandOption{
+			{Type: discord.SubcommandGroupOptionType, Name: "set", Options: []commandOption{
+				{Type: discord.SubcommandOptionType, Name: "color", Options: []commandOption{
+					{Type: discord.StringOptionType, Name: "value"},
+				}},
+			}},
+		},
+	},
+}
+
+// commandPath returns the names of the subcommands given.
+func commandPath(input commandInput) []string {
+	var names []string
+	for _, option := range input.path {
+		names = append(names, option.Name)
+	}
+	return names
+}
+
+// commandValues returns the values given as "name:value".
+func commandValues(input commandInput) []string {
+	var values []string
+	for _, value := range input.values {
+		values = append(values, value.option.Name+":"+value.value)
+	}
+	return values
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text       string
+		wantName   string
+		wantPath   []string
+		wantValues []string
+	}{
+		{"/echo", "echo", nil, nil},
+		{"/echo text:hello", "echo", nil, []string{"text:hello"}},
+		{"/echo text:hello world count:2", "echo", nil, []string{"text:hello world", "count:2"}},
+		{"/echo count:2 text:a:b", "echo", nil, []string{"count:2", "text:a:b"}},
+		{"/echo text:see other:thing", "echo", nil, []string{"text:see other:thing"}},
+		{"/echo stray text:hi", "echo", nil, []string{"text:hi"}},
+		{"/config set", "config", []string{"set"}, nil},
+		{"/config set color value:dark blue", "config", []string{"set", "color"}, []string{"value:dark blue"}},
+	}
+
+	for _, test := range tests {
+		input, ok := parseCommand(test.text, testCommands)
+		if !ok {
+			t.Errorf("parseCommand(%q): not a command", test.text)
+			continue
+		}
+		if input.command.Name != test.wantName {
+			t.Errorf("parseCommand(%q) command = %q, want %q", test.text, input.command.Name, test.wantName)
+		}
+		if path := commandPath(input); !slices.Equal(path, test.wantPath) {
+			t.Errorf("parseCommand(%q) path = %q, want %q", test.text, path, test.wantPath)
+		}
+		if values := commandValues(input); !slices.Equal(values, test.wantValues) {
+			t.Errorf("parseCommand(%q) values = %q, want %q", test.text, values, test.wantValues)
+		}
+	}
+}
+
+func TestParseCommandNotCommand(t *testing.T) {
+	for _, text := range []string{"", "echo text:hi", "/", "/ ", "/unknown", "/ech"} {
+		if _, ok := parseCommand(text, testCommands); ok {
+			t.Errorf("parseCommand(%q): want not a command", text)
+		}
+	}
+}
+
+func TestNeedsSubcommand(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"/echo", false},
+		{"/config", true},
+		{"/config set", true},
+		{"/config set color", false},
+	}
+
+	for _, test := range tests {
+		input, _ := parseCommand(test.text, testCommands)
+		if got := input.needsSubcommand(); got != test.want {
+			t.Errorf("parseCommand(%q).needsSubcommand() = %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestOptionValue(t *testing.T) {
+	c := &composer{chat: &Model{}}
+	channel := &discord.Channel{
+		Type:         discord.DirectMessage,
+		DMRecipients: []discord.User{{ID: 5, Username: "alice"}},
+	}
+
+	colors := []commandChoice{{Name: "Dark Blue", Value: "blue"}, {Name: "Red", Value: "red"}}
+	tests := []struct {
+		optionType discord.CommandOptionType
+		choices    []commandChoice
+		value      string
+		want       any
+	}{
+		{discord.StringOptionType, nil, "hello world", "hello world"},
+		{discord.StringOptionType, nil, " padded ", "padded"},
+		{discord.StringOptionType, colors, "dark blue", "blue"},
+		{discord.StringOptionType, colors, "red", "red"},
+		{discord.StringOptionType, colors, "green", "green"},
+		{discord.IntegerOptionType, nil, "42", int64(42)},
+		{discord.IntegerOptionType, []commandChoice{{Name: "one", Value: float64(1)}}, "one", float64(1)},
+		{discord.NumberOptionType, nil, "1.5", 1.5},
+		{discord.BooleanOptionType, nil, "true", true},
+		{discord.UserOptionType, nil, "<@!123>", "123"},
+		{discord.UserOptionType, nil, "@alice", "5"},
+		{discord.UserOptionType, nil, "123", "123"},
+		{discord.RoleOptionType, nil, "<@&456>", "456"},
+		{discord.ChannelOptionType, nil, "<#789>", "789"},
+		{discord.MentionableOptionType, nil, "<@&456>", "456"},
+	}
+
+	for _, test := range tests {
+		input := optionInput{option: commandOption{Type: test.optionType, Name: "option", Choices: test.choices}, value: test.value}
+		got, err := c.optionValue(channel, input)
+		if err != nil {
+			t.Errorf("optionValue(%d, %q): %v", test.optionType, test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("optionValue(%d, %q) = %#v, want %#v", test.optionType, test.value, got, test.want)
+		}
+	}
+}
+
+func TestOptionValueErrors(t *testing.T) {
+	c := &composer{chat: &Model{}}
+	channel := &discord.Channel{
+		Type:         discord.DirectMessage,
+		DMRecipients: []discord.User{{ID: 5, Username: "alice"}},
+	}
+
+	tests := []struct {
+		optionType discord.CommandOptionType
+		value      string
+	}{
+		{discord.IntegerOptionType, "1.5"},
+		{discord.IntegerOptionType, "many"},
+		{discord.NumberOptionType, "half"},
+		{discord.BooleanOptionType, "maybe"},
+		{discord.UserOptionType, "alice"},
+		{discord.ChannelOptionType, "#general"},
+		{discord.AttachmentOptionType, "cat.png"},
+	}
+
+	for _, test := range tests {
+		input := optionInput{option: commandOption{Type: test.optionType, Name: "option"}, value: test.value}
+		if got, err := c.optionValue(channel, input); err == nil {
+			t.Errorf("optionValue(%d, %q) = %#v, want error", test.optionType, test.value, got)
+		}
+	}
+}
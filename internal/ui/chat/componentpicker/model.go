@@ -0,0 +1,48 @@
+package componentpicker
+
+import (
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/picker"
+)
+
+type Item struct {
+	Label    string
+	Activate tview.Cmd
+}
+
+// Model lists the buttons and select menus of a message, or the options of a
+// select menu.
+type Model struct{ *picker.Model }
+
+func NewModel(cfg *config.Config) *Model {
+	m := &Model{Model: picker.NewModel()}
+	ui.ConfigurePicker(m.Model, cfg, "Components")
+	return m
+}
+
+var _ tview.Model = (*Model)(nil)
+
+func (m *Model) SetItems(title string, items []Item) {
+	pickerItems := make(picker.Items, len(items))
+	for i, item := range items {
+		pickerItems[i] = picker.Item{Text: item.Label, Reference: item.Activate}
+	}
+	m.SetTitle(title)
+	m.Model.SetItems(pickerItems)
+}
+
+func (m *Model) Update(msg tview.Msg) tview.Cmd {
+	switch msg := msg.(type) {
+	case picker.SelectedMsg:
+		activate, ok := msg.Reference.(tview.Cmd)
+		if !ok {
+			return nil
+		}
+		return func() tview.Msg { return SelectedMsg{Activate: activate} }
+	case picker.CancelMsg:
+		return func() tview.Msg { return CancelMsg{} }
+	}
+	return m.Model.Update(msg)
+}
@@ -0,0 +1,9 @@
+package componentpicker
+
+import "github.com/ayn2op/tview"
+
+type SelectedMsg struct {
+	Activate tview.Cmd
+}
+
+type CancelMsg struct{}
@@ -0,0 +1,350 @@
+package chat
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/discordo/internal/ui/chat/componentpicker"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/layers"
+	"github.com/gdamore/tcell/v3"
+)
+
+// Component types arikawa does not have yet, by value.
+const (
+	sectionComponent     discord.ComponentType = 9
+	textDisplayComponent discord.ComponentType = 10
+	containerComponent   discord.ComponentType = 17
+	labelComponent       discord.ComponentType = 18
+)
+
+// Button styles, by value.
+const (
+	primaryButton   = 1
+	secondaryButton = 2
+	successButton   = 3
+	dangerButton    = 4
+	linkButton      = 5
+)
+
+// component is a message or modal component. Components are read through
+// their JSON form: modals arrive in a gateway event the API client has no
+// type for, and both kinds are drawn and submitted the same way.
+type component struct {
+	Type        discord.ComponentType `json:"type"`
+	CustomID    string                `json:"custom_id,omitempty"`
+	Style       int                   `json:"style,omitempty"`
+	Label       string                `json:"label,omitempty"`
+	Emoji       *componentEmoji       `json:"emoji,omitempty"`
+	URL         string                `json:"url,omitempty"`
+	Disabled    bool                  `json:"disabled,omitempty"`
+	Placeholder string                `json:"placeholder,omitempty"`
+	MaxValues   int                   `json:"max_values,omitempty"`
+	Options     []selectOption        `json:"options,omitempty"`
+	Content     string                `json:"content,omitempty"`
+	Value       string                `json:"value,omitempty"`
+	Required    *bool                 `json:"required,omitempty"`
+	Components  []component           `json:"components,omitempty"`
+	// Component is the input of a label and Accessory the button beside a
+	// section.
+	Component *component `json:"component,omitempty"`
+	Accessory *component `json:"accessory,omitempty"`
+}
+
+type componentEmoji struct {
+	ID   discord.EmojiID `json:"id,omitempty"`
+	Name string          `json:"name,omitempty"`
+}
+
+type selectOption struct {
+	Label       string          `json:"label"`
+	Value       string          `json:"value"`
+	Description string          `json:"description,omitempty"`
+	Emoji       *componentEmoji `json:"emoji,omitempty"`
+	Default     bool            `json:"default,omitempty"`
+}
+
+func (e *componentEmoji) String() string {
+	switch {
+	case e == nil || e.Name == "":
+		return ""
+	case e.ID.IsValid():
+		return ":" + e.Name + ": "
+	default:
+		return e.Name + " "
+	}
+}
+
+// messageComponents returns the components of the message, parsing them the
+// first time they are asked for.
+func (ml *messagesList) messageComponents(message discord.Message) []component {
+	if len(message.Components) == 0 {
+		return nil
+	}
+	if components, ok := ml.componentsByID[message.ID]; ok {
+		return components
+	}
+
+	components := parseComponents(message)
+	ml.componentsByID[message.ID] = components
+	return components
+}
+
+func parseComponents(message discord.Message) []component {
+	data, err := json.Marshal(message.Components)
+	if err != nil {
+		slog.Error("failed to marshal message components", "err", err, "message_id", message.ID)
+		return nil
+	}
+	var components []component
+	if err := json.Unmarshal(data, &components); err != nil {
+		slog.Error("failed to unmarshal message components", "err", err, "message_id", message.ID)
+		return nil
+	}
+	return components
+}
+
+// interactiveComponents returns the buttons and select menus, in the order
+// they are drawn.
+func interactiveComponents(components []component) []component {
+	var interactive []component
+	for _, c := range components {
+		switch c.Type {
+		case discord.ButtonComponentType, discord.StringSelectComponentType, discord.UserSelectComponentType, discord.RoleSelectComponentType, discord.MentionableSelectComponentType, discord.ChannelSelectComponentType:
+			interactive = append(interactive, c)
+		case sectionComponent:
+			interactive = append(interactive, interactiveComponents(c.Components)...)
+			if c.Accessory != nil {
+				interactive = append(interactive, interactiveComponents([]component{*c.Accessory})...)
+			}
+		default:
+			interactive = append(interactive, interactiveComponents(c.Components)...)
+		}
+	}
+	return interactive
+}
+
+func (ml *messagesList) drawComponents(builder *tview.LineBuilder, components []component, baseStyle tcell.Style) {
+	for _, c := range components {
+		switch c.Type {
+		case discord.ActionRowComponentType:
+			builder.NewLine()
+			for i, child := range c.Components {
+				if i > 0 {
+					builder.Write(" ", baseStyle)
+				}
+				ml.drawInteractiveComponent(builder, child, baseStyle)
+			}
+		case sectionComponent:
+			ml.drawComponents(builder, c.Components, baseStyle)
+			if c.Accessory != nil && c.Accessory.Type == discord.ButtonComponentType {
+				builder.NewLine()
+				ml.drawInteractiveComponent(builder, *c.Accessory, baseStyle)
+			}
+		case textDisplayComponent:
+			for line := range strings.SplitSeq(c.Content, "\n") {
+				builder.NewLine()
+				builder.Write(line, baseStyle)
+			}
+		case containerComponent:
+			ml.drawComponents(builder, c.Components, baseStyle)
+		}
+	}
+}
+
+func (ml *messagesList) drawInteractiveComponent(builder *tview.LineBuilder, c component, baseStyle tcell.Style) {
+	theme := ml.cfg.Theme.MessagesList.Components
+	var (
+		text  string
+		style tcell.Style
+	)
+	switch c.Type {
+	case discord.ButtonComponentType:
+		text = " " + c.Emoji.String() + c.Label + " "
+		switch c.Style {
+		case primaryButton:
+			style = theme.PrimaryStyle.Style
+		case successButton:
+			style = theme.SuccessStyle.Style
+		case dangerButton:
+			style = theme.DangerStyle.Style
+		case linkButton:
+			text = c.Emoji.String() + c.Label + " ↗"
+			style = theme.LinkStyle.Style
+		default:
+			style = theme.SecondaryStyle.Style
+		}
+	case discord.StringSelectComponentType, discord.UserSelectComponentType, discord.RoleSelectComponentType, discord.MentionableSelectComponentType, discord.ChannelSelectComponentType:
+		text = " " + selectSummary(c) + " ▾ "
+		style = theme.SelectStyle.Style
+	default:
+		return
+	}
+
+	style = tview.MergeStyle(baseStyle, style)
+	if c.Disabled {
+		style = style.Dim(true)
+	}
+	builder.Write(text, style)
+}
+
+// selectSummary returns the options selected by default, or the
+// placeholder.
+func selectSummary(c component) string {
+	var labels []string
+	for _, option := range c.Options {
+		if option.Default {
+			labels = append(labels, option.Label)
+		}
+	}
+	if len(labels) != 0 {
+		return strings.Join(labels, ", ")
+	}
+	if c.Placeholder != "" {
+		return c.Placeholder
+	}
+	return "Make a selection"
+}
+
+func (ml *messagesList) drawEphemeralNotice(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
+	if message.Flags&discord.EphemeralMessage == 0 {
+		return
+	}
+	builder.NewLine()
+	builder.Write("Only you can see this", tview.MergeStyle(baseStyle, ml.cfg.Theme.MessagesList.Components.EphemeralStyle.Style))
+}
+
+func (ml *messagesList) hasInteractiveComponents(message discord.Message) bool {
+	return slices.ContainsFunc(interactiveComponents(ml.messageComponents(message)), func(c component) bool {
+		return !c.Disabled
+	})
+}
+
+func (ml *messagesList) showComponentPicker() tview.Cmd {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok {
+		return nil
+	}
+	message := *selectedMessage
+
+	var items []componentpicker.Item
+	for _, c := range interactiveComponents(ml.messageComponents(message)) {
+		if c.Disabled {
+			continue
+		}
+
+		var item componentpicker.Item
+		switch c.Type {
+		case discord.ButtonComponentType:
+			item.Label = "Button: " + c.Emoji.String() + c.Label
+			if c.Style == linkButton {
+				item.Label = "Link: " + c.Emoji.String() + c.Label
+				item.Activate = openURL(c.URL)
+			} else {
+				item.Activate = ml.chat.sendComponentInteraction(message, c, nil)
+			}
+		case discord.StringSelectComponentType:
+			menu := selectMenu{message: message, component: c}
+			for _, option := range c.Options {
+				if option.Default {
+					menu.values = append(menu.values, option.Value)
+				}
+			}
+			item.Label = "Select: " + selectSummary(c)
+			item.Activate = func() tview.Msg { return selectMenuMsg(menu) }
+		default:
+			item.Label = "Select: " + selectSummary(c)
+			item.Activate = ui.ShowModal("Selecting users, roles or channels is not supported yet.", ui.ModalButton{Label: "Close"})
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	ml.componentPicker.SetItems("Components", items)
+	return ml.openComponentPicker()
+}
+
+func (ml *messagesList) openComponentPicker() tview.Cmd {
+	ml.chat.
+		AddLayer(
+			ui.Centered(ml.componentPicker, ml.cfg.Picker.Width, ml.cfg.Picker.Height),
+			layers.WithName(componentPickerLayerName),
+			layers.WithResize(true),
+			layers.WithVisible(true),
+			layers.WithOverlay(),
+		).
+		SendToFront(componentPickerLayerName)
+	return tview.SetFocus(ml.componentPicker)
+}
+
+// selectMenu is the select menu whose options the component picker lists;
+// values are the options picked so far.
+type selectMenu struct {
+	message   discord.Message
+	component component
+	values    []string
+}
+
+// showSelectOptions lists the options of the select menu. Picking an option
+// of a single-choice menu submits it; multiple-choice menus toggle options
+// until "Submit" is picked.
+func (ml *messagesList) showSelectOptions(menu selectMenu) tview.Cmd {
+	ml.selectMenu = &menu
+	c := menu.component
+
+	var items []componentpicker.Item
+	if c.MaxValues > 1 {
+		items = append(items, componentpicker.Item{
+			Label:    "Submit",
+			Activate: ml.chat.sendComponentInteraction(menu.message, c, menu.values),
+		})
+	}
+	for _, option := range c.Options {
+		label := option.Emoji.String() + option.Label
+		if option.Description != "" {
+			label += " · " + option.Description
+		}
+
+		value := option.Value
+		item := componentpicker.Item{Label: label}
+		if c.MaxValues > 1 {
+			mark := "[ ] "
+			if slices.Contains(menu.values, value) {
+				mark = "[x] "
+			}
+			item.Label = mark + label
+			item.Activate = func() tview.Msg { return toggleSelectOptionMsg(value) }
+		} else {
+			item.Activate = ml.chat.sendComponentInteraction(menu.message, c, []string{value})
+		}
+		items = append(items, item)
+	}
+
+	ml.componentPicker.SetItems(selectSummary(c), items)
+	return ml.openComponentPicker()
+}
+
+func (ml *messagesList) toggleSelectOption(value string) tview.Cmd {
+	if ml.selectMenu == nil {
+		return nil
+	}
+
+	menu := *ml.selectMenu
+	if i := slices.Index(menu.values, value); i >= 0 {
+		menu.values = slices.Delete(slices.Clone(menu.values), i, i+1)
+	} else if len(menu.values) < menu.component.MaxValues {
+		menu.values = append(slices.Clone(menu.values), value)
+	}
+	return ml.showSelectOptions(menu)
+}
+
+func (m *Model) closeComponentPicker() tview.Cmd {
+	m.RemoveLayer(componentPickerLayerName)
+	return tview.SetFocus(m.activeMessagesList())
+}
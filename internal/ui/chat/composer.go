@@ -27,6 +27,7 @@ import (
 	"github.com/ayn2op/ningen/v3"
 	"github.com/ayn2op/ningen/v3/discordmd"
 	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/flex"
 	"github.com/ayn2op/tview/help"
 	"github.com/ayn2op/tview/keybind"
 	"github.com/gdamore/tcell/v3"
@@ -47,6 +48,10 @@ var mentionRegex = regexp.MustCompile("@[a-zA-Z0-9._]+")
 type composer struct {
 	*tview.TextArea
 	chat *Model
+	// messagesList is the list whose channel the composer sends to.
+	messagesList *messagesList
+	// parent is the flex holding the composer, resized to fit the text.
+	parent *flex.Model
 
 	cfg *config.Config
 
@@ -56,6 +61,13 @@ type composer struct {
 	mentionsList    *mentionslist.Model
 	lastSearch      time.Time
 
+	// commands caches the slash commands of each channel.
+	commands map[discord.ChannelID][]applicationCommand
+	// autocompleteNonce identifies the pending autocomplete interaction for
+	// autocompleteOption.
+	autocompleteNonce  string
+	autocompleteOption string
+
 	typingUntil time.Time
 }
 
@@ -72,6 +84,7 @@ func newComposer(cfg *config.Config, chat *Model) *composer {
 		sendMessageData: &api.SendMessageData{},
 		cache:           cache.New(),
 		mentionsList:    mentionslist.NewModel(cfg),
+		commands:        make(map[discord.ChannelID][]applicationCommand),
 	}
 	ui.ConfigureBox(c.Box, &cfg.Theme)
 	c.
@@ -107,14 +120,14 @@ func (c *composer) resizeForContent() {
 	_, _, _, outerH := c.Rect()
 	_, _, _, innerH := c.InnerRect()
 	frame := outerH - innerH
-	_, _, _, parentH := c.chat.rightFlex.InnerRect()
+	_, _, _, parentH := c.parent.InnerRect()
 
 	visible := min(
 		strings.Count(c.Text(), "\n")+1,
 		max(c.cfg.Composer.MaxHeight, 1),
 		max(parentH-frame-1, 1),
 	)
-	c.chat.rightFlex.ResizeItem(c, visible+frame, 1)
+	c.parent.ResizeItem(c, visible+frame, 1)
 	c.SetVisibleSize(0, visible)
 
 	total := c.LineCount(0)
@@ -168,7 +181,7 @@ func (c *composer) Update(msg tview.Msg) tview.Cmd {
 		c.attach(imageAttachmentName, bytes.NewReader(msg))
 		return nil
 	case filesPickedMsg:
-		selectedChannel, ok := c.chat.SelectedChannel()
+		selectedChannel, ok := c.messagesList.selectedChannel()
 		if !ok || selectedChannel.ID != msg.channelID {
 			return closeFiles(msg.files)
 		}
@@ -205,6 +218,9 @@ func (c *composer) Update(msg tview.Msg) tview.Cmd {
 			if c.chat.GetVisible(mentionsListLayerName) {
 				return c.tabComplete()
 			}
+			if c.commandMode() {
+				return c.autocompleteCommand()
+			}
 			return c.forwardToTextArea(msg)
 		case keybind.Matches(msg, c.cfg.Keybinds.Composer.Undo.Keybind):
 			return c.forwardToTextArea(tcell.NewEventKey(tcell.KeyCtrlZ, "", tcell.ModNone))
@@ -212,7 +228,9 @@ func (c *composer) Update(msg tview.Msg) tview.Cmd {
 
 		typingCmd := c.sendTyping()
 
-		if c.cfg.AutocompleteLimit > 0 {
+		// Commands listed with the tab key are kept up to date even when
+		// suggestions are not shown while typing.
+		if c.cfg.AutocompleteLimit > 0 || c.chat.GetVisible(mentionsListLayerName) {
 			if c.chat.GetVisible(mentionsListLayerName) {
 				keybinds := c.cfg.Keybinds.MentionsList
 				if keybind.Matches(msg, keybinds.SelectUp.Keybind) ||
@@ -249,7 +267,7 @@ type filesPickedMsg struct {
 }
 
 func (c *composer) pickFiles() tview.Cmd {
-	selectedChannel, ok := c.chat.SelectedChannel()
+	selectedChannel, ok := c.messagesList.selectedChannel()
 	if !ok {
 		return nil
 	}
@@ -300,7 +318,7 @@ func (c *composer) sendTyping() tview.Cmd {
 	}
 	c.typingUntil = now.Add(typingDuration)
 
-	selectedChannel, ok := c.chat.SelectedChannel()
+	selectedChannel, ok := c.messagesList.selectedChannel()
 	if !ok {
 		return nil
 	}
@@ -312,7 +330,7 @@ func (c *composer) sendTyping() tview.Cmd {
 }
 
 func (c *composer) send() tview.Cmd {
-	selectedChannel, ok := c.chat.SelectedChannel()
+	selectedChannel, ok := c.messagesList.selectedChannel()
 	if !ok {
 		return nil
 	}
@@ -322,6 +340,12 @@ func (c *composer) send() tview.Cmd {
 		return nil
 	}
 
+	if !c.edit {
+		if cmd, ok := c.sendCommand(selectedChannel, text); ok {
+			return cmd
+		}
+	}
+
 	text = c.processText(selectedChannel, []byte(text))
 	data := *c.sendMessageData
 	data.Files = slices.Clone(data.Files)
@@ -329,7 +353,7 @@ func (c *composer) send() tview.Cmd {
 	var editMessage discord.Message
 	edit := c.edit
 	if edit {
-		selectedMessage, ok := c.chat.messagesList.selectedMessage()
+		selectedMessage, ok := c.messagesList.selectedMessage()
 		if !ok {
 			return nil
 		}
@@ -338,10 +362,10 @@ func (c *composer) send() tview.Cmd {
 
 	c.typingUntil = time.Time{}
 	c.reset()
-	c.chat.messagesList.clearSelection()
-	c.chat.messagesList.ScrollBottom()
+	c.messagesList.clearSelection()
+	c.messagesList.ScrollBottom()
 
-	return func() tview.Msg {
+	send := func() tview.Msg {
 		defer closeFiles(data.Files)()
 		if edit {
 			editData := api.EditMessageData{Content: option.SomeNullable(text)}
@@ -356,6 +380,11 @@ func (c *composer) send() tview.Cmd {
 		}
 		return nil
 	}
+	// The sent message would land beyond an older window of history.
+	if !edit && c.messagesList.detached {
+		return tview.Sequence(send, c.messagesList.jumpToPresent())
+	}
+	return send
 }
 
 func (c *composer) processText(channel *discord.Channel, src []byte) string {
@@ -434,13 +463,17 @@ func isMentionChar(r rune) bool {
 }
 
 func (c *composer) tabComplete() tview.Cmd {
+	if c.commandMode() {
+		return c.completeCommand()
+	}
+
 	posEnd, name, r := c.GetWordUnderCursor(isMentionChar)
 	if r != '@' {
 		return c.stopTabCompletion()
 	}
 	pos := posEnd - (len(name) + 1)
 
-	selectedChannel, ok := c.chat.SelectedChannel()
+	selectedChannel, ok := c.messagesList.selectedChannel()
 	if !ok {
 		return nil
 	}
@@ -484,17 +517,22 @@ func (c *composer) tabComplete() tview.Cmd {
 }
 
 func (c *composer) tabSuggest() tview.Cmd {
+	if c.commandMode() {
+		return c.suggestCommand(false)
+	}
+
 	_, name, r := c.GetWordUnderCursor(isMentionChar)
 	if r != '@' {
 		return c.stopTabCompletion()
 	}
-	selectedChannel, ok := c.chat.SelectedChannel()
+	selectedChannel, ok := c.messagesList.selectedChannel()
 	if !ok {
 		return nil
 	}
 	gID := selectedChannel.GuildID
 	cID := selectedChannel.ID
 	c.mentionsList.Clear()
+	c.mentionsList.SetTitle("Mentions")
 
 	var shown map[string]struct{}
 	var userDone struct{}
@@ -669,7 +707,7 @@ func (c *composer) showMentionsList() tview.Cmd {
 	l := c.mentionsList
 	x, _, _, _ := c.InnerRect()
 	_, y, _, _ := c.Rect()
-	_, _, maxW, maxH := c.chat.messagesList.InnerRect()
+	_, _, maxW, maxH := c.messagesList.InnerRect()
 	if t := int(c.cfg.Theme.MentionsList.MaxHeight); t != 0 {
 		maxH = min(maxH, t)
 	}
@@ -756,7 +794,7 @@ func (c *composer) removeMentionsList() {
 }
 
 func (c *composer) stopTabCompletion() tview.Cmd {
-	if c.cfg.AutocompleteLimit > 0 {
+	if c.cfg.AutocompleteLimit > 0 || c.chat.GetVisible(mentionsListLayerName) {
 		c.mentionsList.Clear()
 		c.removeMentionsList()
 		return tview.SetFocus(c)
@@ -815,7 +853,7 @@ func (c *composer) attach(name string, reader io.Reader) {
 }
 
 func (c *composer) canAttachFiles() bool {
-	selectedChannel, ok := c.chat.SelectedChannel()
+	selectedChannel, ok := c.messagesList.selectedChannel()
 	return ok && c.chat.state.HasPermissions(selectedChannel.ID, discord.PermissionAttachFiles)
 }
 
@@ -0,0 +1,113 @@
+//go:build ignore
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const emojiTestURL = "https://unicode.org/Public/emoji/latest/emoji-test.txt"
+
+const header = `// Code generated by go generate; DO NOT EDIT.
+
+package %s
+
+var unicodeEmojis = []unicodeEmoji{
+`
+
+func fetchEmojiTest() ([]byte, error) {
+	resp, err := http.Get(emojiTestURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("failed to fetch emoji test data; status=%q", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// isSkinTone reports whether the code point is a Fitzpatrick modifier. Skin
+// tone variants are skipped to keep the picker list short.
+func isSkinTone(codePoint string) bool {
+	switch codePoint {
+	case "1F3FB", "1F3FC", "1F3FD", "1F3FE", "1F3FF":
+		return true
+	default:
+		return false
+	}
+}
+
+func main() {
+	out := flag.String("out", "generated.go", "out filename")
+	flag.Parse()
+
+	data, err := fetchEmojiTest()
+	if err != nil {
+		panic(err)
+	}
+
+	packageName := os.Getenv("GOPACKAGE")
+	if packageName == "" {
+		panic("GOPACKAGE is not set; run with go generate")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, header, packageName)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// <code points> ; fully-qualified # <emoji> E<version> <name>
+		codePoints, rest, ok := strings.Cut(line, ";")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		status, comment, ok := strings.Cut(rest, "#")
+		if !ok || strings.TrimSpace(status) != "fully-qualified" {
+			continue
+		}
+
+		var emoji strings.Builder
+		skip := false
+		for _, codePoint := range strings.Fields(codePoints) {
+			if isSkinTone(codePoint) {
+				skip = true
+				break
+			}
+			r, err := strconv.ParseInt(codePoint, 16, 32)
+			if err != nil {
+				panic(err)
+			}
+			emoji.WriteRune(rune(r))
+		}
+		if skip {
+			continue
+		}
+
+		// Drop the emoji itself and the version ("E1.0") from the comment.
+		fields := strings.Fields(comment)
+		if len(fields) < 3 {
+			continue
+		}
+		name := strings.Join(fields[2:], " ")
+		fmt.Fprintf(&b, "\t{%q, %q},\n", emoji.String(), name)
+	}
+	if err := scanner.Err(); err != nil {
+		panic(err)
+	}
+	b.WriteString("}\n")
+
+	if err := os.WriteFile(*out, b.Bytes(), 0644); err != nil {
+		panic(err)
+	}
+}
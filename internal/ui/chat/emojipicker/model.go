@@ -0,0 +1,88 @@
+package emojipicker
+
+//go:generate go run generator.go
+
+import (
+	"strconv"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/picker"
+)
+
+type unicodeEmoji struct {
+	emoji string
+	name  string
+}
+
+type Model struct {
+	*picker.Model
+}
+
+func NewModel(cfg *config.Config) *Model {
+	m := &Model{Model: picker.NewModel()}
+	ui.ConfigurePicker(m.Model, cfg, "Emojis")
+	return m
+}
+
+var _ tview.Model = (*Model)(nil)
+
+func (m *Model) Update(msg tview.Msg) tview.Cmd {
+	switch msg := msg.(type) {
+	case picker.SelectedMsg:
+		emoji, ok := msg.Reference.(discord.APIEmoji)
+		if !ok || emoji == "" {
+			return nil
+		}
+		return func() tview.Msg { return SelectedMsg{Emoji: emoji} }
+	case picker.CancelMsg:
+		return func() tview.Msg { return CancelMsg{} }
+	}
+	return m.Model.Update(msg)
+}
+
+// SetEmojis lists the custom emojis of the current guild (if any) followed by
+// every unicode emoji.
+func (m *Model) SetEmojis(custom []discord.Emoji) {
+	items := make(picker.Items, 0, len(custom)+len(unicodeEmojis))
+	for _, emoji := range custom {
+		// Emojis can become unavailable when the guild loses boosts.
+		if !emoji.Available {
+			continue
+		}
+
+		text := ":" + emoji.Name + ":"
+		items = append(items, picker.Item{Text: text, FilterText: emoji.Name, Reference: emoji.APIString()})
+	}
+
+	for _, emoji := range unicodeEmojis {
+		items = append(items, picker.Item{
+			Text:       emoji.emoji + " " + emoji.name,
+			FilterText: emoji.name,
+			Reference:  discord.APIEmoji(emoji.emoji),
+		})
+	}
+
+	m.SetItems(items)
+}
+
+// SetReactions lists the reactions already present on a message.
+func (m *Model) SetReactions(reactions []discord.Reaction) {
+	items := make(picker.Items, 0, len(reactions))
+	for _, reaction := range reactions {
+		name := reaction.Emoji.Name
+		if reaction.Emoji.IsCustom() {
+			name = ":" + name + ":"
+		}
+
+		text := name + " " + strconv.Itoa(reaction.Count)
+		if reaction.Me {
+			text += " (reacted)"
+		}
+		items = append(items, picker.Item{Text: text, FilterText: reaction.Emoji.Name, Reference: reaction.Emoji.APIString()})
+	}
+
+	m.SetItems(items)
+}
@@ -0,0 +1,9 @@
+package emojipicker
+
+import "github.com/ayn2op/arikawa/v3/discord"
+
+type SelectedMsg struct {
+	Emoji discord.APIEmoji
+}
+
+type CancelMsg struct{}
@@ -32,6 +32,10 @@ type guildsTree struct {
 	guildNodeByID   map[discord.GuildID]*tree.Node
 	channelNodeByID map[discord.ChannelID]*tree.Node
 	dmRootNode      *tree.Node
+	// folderNodeByGuildID maps the guilds in folders to their folder.
+	folderNodeByGuildID map[discord.GuildID]*tree.Node
+	// badged holds the nodes whose line ends with a mention badge.
+	badged map[*tree.Node]struct{}
 }
 
 func newGuildsTree(cfg *config.Config, state *ningen.State) *guildsTree {
@@ -40,8 +44,10 @@ func newGuildsTree(cfg *config.Config, state *ningen.State) *guildsTree {
 		cfg:   cfg,
 		state: state,
 
-		guildNodeByID:   make(map[discord.GuildID]*tree.Node),
-		channelNodeByID: make(map[discord.ChannelID]*tree.Node),
+		guildNodeByID:       make(map[discord.GuildID]*tree.Node),
+		channelNodeByID:     make(map[discord.ChannelID]*tree.Node),
+		folderNodeByGuildID: make(map[discord.GuildID]*tree.Node),
+		badged:              make(map[*tree.Node]struct{}),
 	}
 	ui.ConfigureBox(gt.Box, &cfg.Theme)
 	gt.
@@ -71,6 +77,8 @@ func (gt *guildsTree) resetNodeIndex() {
 	// Keep allocated map capacity; READY can rebuild often during reconnects.
 	clear(gt.guildNodeByID)
 	clear(gt.channelNodeByID)
+	clear(gt.folderNodeByGuildID)
+	clear(gt.badged)
 	gt.dmRootNode = nil
 }
 
@@ -103,12 +111,17 @@ func (gt *guildsTree) createFolderNode(folder gateway.GuildFolder, guildsByID ma
 	for _, guildID := range folder.GuildIDs {
 		if guildEvent, ok := guildsByID[guildID]; ok {
 			gt.createGuildNode(folderNode, guildEvent.Guild)
+			gt.folderNodeByGuildID[guildID] = folderNode
 		}
 	}
+	gt.setNodeBadge(folderNode, gt.folderMentions(folderNode))
 }
 
 func (gt *guildsTree) unreadStyle(indication ningen.UnreadIndication) tcell.Style {
 	var style tcell.Style
+	if !gt.cfg.Sidebar.UnreadIndicator.Styles() {
+		return style
+	}
 	switch indication {
 	case ningen.ChannelRead:
 		style = style.Dim(true)
@@ -123,12 +136,18 @@ func (gt *guildsTree) unreadStyle(indication ningen.UnreadIndication) tcell.Styl
 }
 
 func (gt *guildsTree) guildNodeStyle(guildID discord.GuildID) tcell.Style {
+	if gt.guildMuted(guildID) {
+		return mutedStyle
+	}
 	indication := gt.state.GuildIsUnread(guildID, ningen.GuildUnreadOpts{IncludeMutedCategories: true})
 	return gt.unreadStyle(indication)
 }
 
 func (gt *guildsTree) channelNodeStyle(channel discord.Channel) tcell.Style {
 	unread := gt.unreadStyle(gt.state.ChannelIsUnread(channel.ID, ningen.UnreadOpts{IncludeMutedCategories: true}))
+	if gt.channelMuted(channel.ID) {
+		unread = mutedStyle
+	}
 	if channel.Type != discord.DirectMessage || len(channel.DMRecipients) != 1 {
 		return unread
 	}
@@ -162,6 +181,7 @@ func (gt *guildsTree) createGuildNode(parent *tree.Node, guild discord.Guild) {
 		SetExpanded(false).
 		SetIndent(gt.cfg.Sidebar.Indents.Guild)
 	gt.setNodeLineStyle(guildNode, gt.guildNodeStyle(guild.ID))
+	gt.setNodeBadge(guildNode, gt.guildMentions(guild.ID))
 	parent.AddChild(guildNode)
 	gt.guildNodeByID[guild.ID] = guildNode
 }
@@ -174,6 +194,7 @@ func (gt *guildsTree) createChannelNode(parent *tree.Node, channel discord.Chann
 	indents := gt.cfg.Sidebar.Indents
 	channelNode := tree.NewNode(ui.ChannelToString(channel, gt.cfg.Icons, gt.state)).SetReference(channel.ID)
 	gt.setNodeLineStyle(channelNode, gt.channelNodeStyle(channel))
+	gt.setNodeBadge(channelNode, gt.channelMentions(channel.ID))
 	switch channel.Type {
 	case discord.DirectMessage:
 		channelNode.SetIndent(indents.DM)
@@ -192,9 +213,11 @@ func (gt *guildsTree) createChannelNode(parent *tree.Node, channel discord.Chann
 	gt.channelNodeByID[channel.ID] = channelNode
 }
 
+// setNodeLineStyle styles the label of the node; its badge keeps its own
+// style.
 func (gt *guildsTree) setNodeLineStyle(node *tree.Node, style tcell.Style) {
 	line := node.Line()
-	for i := range line {
+	for i := range gt.labelLen(node) {
 		line[i].Style = style
 	}
 	node.SetLine(line)
@@ -240,6 +263,53 @@ func (gt *guildsTree) createChannelNodes(node *tree.Node, channels []discord.Cha
 	}
 }
 
+// onThreadUpdate keeps the tree in sync as threads are created, archived and
+// unarchived. Threads of channels that were never expanded are created lazily
+// by createChannelNodes instead.
+func (gt *guildsTree) onThreadUpdate(thread discord.Channel) {
+	if thread.ThreadMetadata != nil && thread.ThreadMetadata.Archived {
+		gt.removeChannelNode(thread.ID)
+		return
+	}
+
+	if _, ok := gt.channelNodeByID[thread.ID]; ok {
+		return
+	}
+
+	parent := gt.channelNodeByID[thread.ParentID]
+	if parent == nil {
+		return
+	}
+
+	// Forums load their threads when expanded.
+	if len(parent.Children()) == 0 {
+		if channel, err := gt.state.Cabinet.Channel(thread.ParentID); err == nil && channel.Type == discord.GuildForum {
+			return
+		}
+	}
+	gt.createChannelNode(parent, thread)
+}
+
+func (gt *guildsTree) removeChannelNode(channelID discord.ChannelID) {
+	node := gt.channelNodeByID[channelID]
+	if node == nil {
+		return
+	}
+
+	path := gt.GetPath(node)
+	if len(path) < 2 {
+		return
+	}
+
+	parent := path[len(path)-2]
+	parent.RemoveChild(node)
+	delete(gt.channelNodeByID, channelID)
+	delete(gt.badged, node)
+	if gt.CurrentNode() == node {
+		gt.SetCurrentNode(parent)
+	}
+}
+
 func isThread(t discord.ChannelType) bool {
 	switch t {
 	case discord.GuildPublicThread, discord.GuildPrivateThread, discord.GuildAnnouncementThread:
@@ -320,12 +390,40 @@ func (gt *guildsTree) loadChannel(channel discord.Channel) tview.Cmd {
 			return nil
 		}
 
+		// Capture the read marker before marking the channel as read so the
+		// messages list can show where the user stopped reading.
+		var lastReadID discord.MessageID
+		if readState := gt.state.ReadState.ReadState(channel.ID); readState != nil {
+			lastReadID = readState.LastMessageID
+		}
+
 		// The tree node may hold an older channel snapshot.
-		if lastMessageID := gt.state.LastMessage(channel.ID); lastMessageID.IsValid() {
+		lastMessageID := gt.state.LastMessage(channel.ID)
+		if lastMessageID.IsValid() {
 			go gt.state.ReadState.MarkRead(channel.ID, lastMessageID)
 		}
+		if lastReadID >= lastMessageID {
+			lastReadID = 0
+		}
+
+		return channelLoadedMsg{Channel: channel, Messages: messages, LastReadID: lastReadID}
+	}
+}
+
+// loadChannelAround loads a window of messages centered on messageID. The
+// channel is not marked as read since the latest messages are not shown.
+func (gt *guildsTree) loadChannelAround(channel discord.Channel, messageID discord.MessageID) tview.Cmd {
+	limit := uint(gt.cfg.MessagesLimit)
+	return func() tview.Msg {
+		messages, err := gt.state.MessagesAround(channel.ID, messageID, limit)
+		if err != nil {
+			slog.Error("failed to get messages around message", "err", err, "channel_id", channel.ID, "message_id", messageID, "limit", limit)
+			return nil
+		}
 
-		return channelLoadedMsg{Channel: channel, Messages: messages}
+		// Messages are returned newest first.
+		detached := len(messages) > 0 && messages[0].ID < gt.state.LastMessage(channel.ID)
+		return channelLoadedMsg{Channel: channel, Messages: messages, TargetID: messageID, Detached: detached}
 	}
 }
 
@@ -358,6 +456,12 @@ func (gt *guildsTree) Update(msg tview.Msg) tview.Cmd {
 			return nil
 		case keybind.Matches(msg, gt.cfg.Keybinds.GuildsTree.YankID.Keybind):
 			return gt.yankID()
+		case keybind.Matches(msg, gt.cfg.Keybinds.GuildsTree.NotificationSettings.Keybind):
+			return gt.showNotificationSettings()
+		case keybind.Matches(msg, gt.cfg.Keybinds.GuildsTree.MarkRead.Keybind):
+			return gt.markNodeRead()
+		case keybind.Matches(msg, gt.cfg.Keybinds.GuildsTree.MarkAllRead.Keybind):
+			return gt.confirmMarkAllRead()
 		}
 	}
 	return gt.Model.Update(msg)
@@ -478,6 +582,7 @@ func (gt *guildsTree) FullHelp() [][]keybind.Keybind {
 	return [][]keybind.Keybind{
 		{cfg.SelectUp.Keybind, cfg.SelectDown.Keybind, cfg.SelectTop.Keybind, cfg.SelectBottom.Keybind},
 		selectGroup,
+		{cfg.MarkRead.Keybind, cfg.MarkAllRead.Keybind, cfg.NotificationSettings.Keybind},
 		{cfg.YankID.Keybind},
 	}
 }
@@ -0,0 +1,218 @@
+package chat
+
+import (
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/consts"
+	"github.com/ayn2op/discordo/internal/images"
+	"github.com/ayn2op/tview"
+	"github.com/gdamore/tcell/v3"
+)
+
+// thumbnailSize is the largest side, in pixels, of the thumbnails requested
+// from the media proxy.
+const thumbnailSize = 512
+
+// imagePreviews renders image attachments and embed images inline.
+type imagePreviews struct {
+	cfg   *config.Config
+	cache *images.Cache
+	// kitty and sixel are nil when previews are drawn with half blocks.
+	kitty *images.Kitty
+	sixel *images.Sixel
+}
+
+// newImagePreviews returns nil when previews are disabled.
+func newImagePreviews(cfg *config.Config) *imagePreviews {
+	if !cfg.Images.Enabled {
+		return nil
+	}
+
+	ip := &imagePreviews{
+		cfg:   cfg,
+		cache: images.NewCache(filepath.Join(consts.CacheDir(), "images")),
+	}
+
+	protocol := images.Detect(images.Protocol(cfg.Images.Protocol), os.Getenv)
+	switch protocol {
+	case images.ProtocolKitty:
+		// Graphics commands bypass the screen and go straight to the
+		// terminal; they do not move the cursor or touch any cell.
+		tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			slog.Error("failed to open terminal; falling back to half blocks", "err", err)
+		} else {
+			ip.kitty = images.NewKitty(tty)
+		}
+	case images.ProtocolSixel:
+		// Sixel images are painted over the drawn frame by DrawImages.
+		ip.sixel = images.NewSixel(ip.cache)
+	}
+	return ip
+}
+
+// lines returns the preview of the image, or nil while it is loading.
+// maxCols is the width available in the messages list.
+func (ip *imagePreviews) lines(img previewImage, maxCols int) []tview.Line {
+	maxRows := ip.cfg.Images.MaxHeight
+	switch {
+	case ip.kitty != nil:
+		maxRows = min(maxRows, images.KittyMaxRows)
+	case ip.sixel != nil:
+		maxRows = min(maxRows, images.SixelMaxRows)
+	}
+
+	cols, rows := images.Fit(img.Width, img.Height, min(ip.cfg.Images.MaxWidth, maxCols), maxRows)
+	if cols == 0 {
+		return nil
+	}
+
+	thumbnailURL := img.thumbnailURL()
+	thumbnail, ok := ip.cache.Get(thumbnailURL)
+	if !ok {
+		return nil
+	}
+	switch {
+	case ip.kitty != nil:
+		return ip.kitty.Lines(thumbnailURL, thumbnail, cols, rows)
+	case ip.sixel != nil && ip.sixel.Supported():
+		return ip.sixel.Lines(thumbnailURL, cols, rows)
+	}
+	return images.HalfBlocks(thumbnail, cols, rows)
+}
+
+// previewImage is an image attachment or embed image; Width and Height are
+// its size in pixels.
+type previewImage struct {
+	ProxyURL      string
+	Width, Height int
+}
+
+// thumbnailURL asks the media proxy for a PNG no larger than thumbnailSize,
+// keeping the aspect ratio.
+func (img previewImage) thumbnailURL() string {
+	u, err := url.Parse(img.ProxyURL)
+	if err != nil {
+		return img.ProxyURL
+	}
+
+	width, height := img.Width, img.Height
+	if width > thumbnailSize || height > thumbnailSize {
+		if width >= height {
+			height = max(height*thumbnailSize/width, 1)
+			width = thumbnailSize
+		} else {
+			width = max(width*thumbnailSize/height, 1)
+			height = thumbnailSize
+		}
+	}
+
+	query := u.Query()
+	query.Set("format", "png")
+	query.Set("width", strconv.Itoa(width))
+	query.Set("height", strconv.Itoa(height))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func attachmentImage(attachment discord.Attachment) (previewImage, bool) {
+	if !strings.HasPrefix(attachment.ContentType, "image/") || attachment.Proxy == "" || attachment.Width == 0 || attachment.Height == 0 {
+		return previewImage{}, false
+	}
+	return previewImage{attachment.Proxy, int(attachment.Width), int(attachment.Height)}, true
+}
+
+// embedImage returns the image of the embed, or its thumbnail when it has no
+// image.
+func embedImage(embed discord.Embed) (previewImage, bool) {
+	var img previewImage
+	switch {
+	case embed.Image != nil:
+		img = previewImage{embed.Image.Proxy, int(embed.Image.Width), int(embed.Image.Height)}
+	case embed.Thumbnail != nil:
+		img = previewImage{embed.Thumbnail.Proxy, int(embed.Thumbnail.Width), int(embed.Thumbnail.Height)}
+	}
+	return img, img.ProxyURL != "" && img.Width > 0 && img.Height > 0
+}
+
+func messageImages(message discord.Message) []previewImage {
+	var imgs []previewImage
+	for _, attachment := range message.Attachments {
+		if img, ok := attachmentImage(attachment); ok {
+			imgs = append(imgs, img)
+		}
+	}
+	for _, embed := range message.Embeds {
+		if img, ok := embedImage(embed); ok {
+			imgs = append(imgs, img)
+		}
+	}
+	return imgs
+}
+
+func (ml *messagesList) drawImagePreview(builder *tview.LineBuilder, img previewImage) {
+	if ml.chat.imagePreviews == nil {
+		return
+	}
+
+	lines := ml.chat.imagePreviews.lines(img, ml.bodyWidth())
+	if len(lines) == 0 {
+		return
+	}
+	builder.NewLine()
+	builder.AppendLines(lines)
+}
+
+func (ml *messagesList) drawEmbedImages(builder *tview.LineBuilder, message discord.Message) {
+	for _, embed := range message.Embeds {
+		if img, ok := embedImage(embed); ok {
+			ml.drawImagePreview(builder, img)
+		}
+	}
+}
+
+// DrawImages paints the image previews that are drawn over the frame rather
+// than in its cells. It must be called once the whole frame is drawn.
+func (m *Model) DrawImages(screen tcell.Screen) {
+	if m.imagePreviews == nil || m.imagePreviews.sixel == nil {
+		return
+	}
+	m.imagePreviews.sixel.Place(screen)
+}
+
+func (m *Model) listenImages() tview.Cmd {
+	if m.imagePreviews == nil {
+		return nil
+	}
+	loaded := m.imagePreviews.cache.Loaded()
+	return func() tview.Msg {
+		return imageLoadedMsg{URL: <-loaded}
+	}
+}
+
+// onImageLoaded re-renders the messages previewing the image.
+func (m *Model) onImageLoaded(msg imageLoadedMsg) tview.Cmd {
+	for _, ml := range []*messagesList{m.messagesList, m.threadPane.messagesList} {
+		changed := false
+		for _, message := range ml.messages {
+			if slices.ContainsFunc(messageImages(message), func(img previewImage) bool {
+				return img.thumbnailURL() == msg.URL
+			}) {
+				delete(ml.itemByID, message.ID)
+				changed = true
+			}
+		}
+		if changed {
+			ml.SetBuilder(ml.buildItem)
+		}
+	}
+	return m.listenImages()
+}
@@ -0,0 +1,256 @@
+package chat
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/api"
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/arikawa/v3/gateway"
+	"github.com/ayn2op/arikawa/v3/utils/httputil"
+	"github.com/ayn2op/arikawa/v3/utils/ws"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/layers"
+)
+
+// interactionRequest is what user clients send to use a component or submit
+// a modal; the application answers through the gateway.
+type interactionRequest struct {
+	Type          discord.InteractionDataType `json:"type"`
+	Nonce         string                      `json:"nonce"`
+	ApplicationID discord.AppID               `json:"application_id"`
+	GuildID       discord.GuildID             `json:"guild_id,omitempty"`
+	ChannelID     discord.ChannelID           `json:"channel_id"`
+	MessageID     discord.MessageID           `json:"message_id,omitempty"`
+	MessageFlags  discord.MessageFlags        `json:"message_flags,omitempty"`
+	SessionID     string                      `json:"session_id"`
+	Data          any                         `json:"data"`
+}
+
+type componentInteractionData struct {
+	ComponentType discord.ComponentType `json:"component_type"`
+	CustomID      string                `json:"custom_id"`
+	Type          discord.ComponentType `json:"type,omitempty"`
+	Values        []string              `json:"values,omitempty"`
+}
+
+type modalSubmitData struct {
+	ID         discord.Snowflake `json:"id"`
+	CustomID   string            `json:"custom_id"`
+	Components []modalRow        `json:"components"`
+}
+
+// modalRow is an action row or a label holding the value of a text input.
+type modalRow struct {
+	Type       discord.ComponentType `json:"type"`
+	Components []modalValue          `json:"components,omitempty"`
+	Component  *modalValue           `json:"component,omitempty"`
+}
+
+type modalValue struct {
+	Type     discord.ComponentType `json:"type"`
+	CustomID string                `json:"custom_id"`
+	Value    string                `json:"value"`
+}
+
+func newNonce() string {
+	return discord.NewSnowflake(time.Now()).String()
+}
+
+// sendInteraction sends the interaction as the current gateway session, so
+// that the response, such as a modal or an ephemeral message, comes back to
+// it. A nonce is generated unless the request has one.
+func (m *Model) sendInteraction(req interactionRequest) tview.Cmd {
+	if req.Nonce == "" {
+		req.Nonce = newNonce()
+	}
+	req.SessionID = m.sessionID
+	return func() tview.Msg {
+		if err := m.state.FastRequest("POST", api.Endpoint+"interactions", httputil.WithJSONBody(req)); err != nil {
+			slog.Error("failed to send interaction", "err", err, "type", req.Type, "channel_id", req.ChannelID, "message_id", req.MessageID)
+			return ui.ModalMsg{Text: "Failed to reach the application: " + err.Error(), Buttons: []ui.ModalButton{{Label: "Close"}}}
+		}
+		return nil
+	}
+}
+
+// sendComponentInteraction uses the button or select menu of the message;
+// values are the options picked in a select menu.
+func (m *Model) sendComponentInteraction(message discord.Message, c component, values []string) tview.Cmd {
+	data := componentInteractionData{ComponentType: c.Type, CustomID: c.CustomID}
+	if c.Type != discord.ButtonComponentType {
+		data.Type = c.Type
+		data.Values = values
+	}
+
+	applicationID := message.ApplicationID
+	if !applicationID.IsValid() {
+		applicationID = discord.AppID(message.Author.ID)
+	}
+	return m.sendInteraction(interactionRequest{
+		Type:          discord.ComponentInteractionType,
+		ApplicationID: applicationID,
+		GuildID:       message.GuildID,
+		ChannelID:     message.ChannelID,
+		MessageID:     message.ID,
+		MessageFlags:  message.Flags,
+		Data:          data,
+	})
+}
+
+// dispatchOp is the opcode of gateway events.
+const dispatchOp ws.OpCode = 0
+
+// interactionModalCreateEvent asks the user to fill in a modal in response to
+// an interaction.
+type interactionModalCreateEvent struct {
+	ID          discord.Snowflake `json:"id"`
+	Nonce       string            `json:"nonce"`
+	ChannelID   discord.ChannelID `json:"channel_id"`
+	CustomID    string            `json:"custom_id"`
+	Title       string            `json:"title"`
+	Components  []component       `json:"components"`
+	Application struct {
+		ID discord.AppID `json:"id"`
+	} `json:"application"`
+}
+
+// interactionFailureEvent reports that the application did not respond to an
+// interaction in time.
+type interactionFailureEvent struct {
+	ID    discord.Snowflake `json:"id"`
+	Nonce string            `json:"nonce"`
+}
+
+func (*interactionModalCreateEvent) Op() ws.OpCode { return dispatchOp }
+func (*interactionModalCreateEvent) EventType() ws.EventType {
+	return "INTERACTION_MODAL_CREATE"
+}
+
+func (*interactionFailureEvent) Op() ws.OpCode { return dispatchOp }
+func (*interactionFailureEvent) EventType() ws.EventType {
+	return "INTERACTION_FAILURE"
+}
+
+func init() {
+	gateway.OpUnmarshalers.Add(
+		func() ws.Event { return new(interactionModalCreateEvent) },
+		func() ws.Event { return new(interactionFailureEvent) },
+		func() ws.Event { return new(autocompleteResponseEvent) },
+	)
+}
+
+// modalInput is a text input of a modal; labelled inputs are submitted
+// inside their label, the others inside an action row.
+type modalInput struct {
+	component
+	labelled bool
+}
+
+// interactionModal is a modal of an application, shown as a form.
+type interactionModal struct {
+	*tview.Form
+	chat   *Model
+	event  *interactionModalCreateEvent
+	inputs []modalInput
+}
+
+var _ tview.Model = (*interactionModal)(nil)
+
+func newInteractionModal(cfg *config.Config, chat *Model, event *interactionModalCreateEvent) *interactionModal {
+	im := &interactionModal{Form: tview.NewForm(), chat: chat, event: event}
+	for _, c := range event.Components {
+		switch c.Type {
+		case discord.ActionRowComponentType:
+			for _, child := range c.Components {
+				if child.Type == discord.TextInputComponentType {
+					im.inputs = append(im.inputs, modalInput{component: child})
+				}
+			}
+		case labelComponent:
+			if c.Component != nil && c.Component.Type == discord.TextInputComponentType {
+				input := *c.Component
+				input.Label = c.Label
+				im.inputs = append(im.inputs, modalInput{component: input, labelled: true})
+			}
+		}
+	}
+
+	for _, input := range im.inputs {
+		label := input.Label
+		if input.Required == nil || *input.Required {
+			label += " *"
+		}
+		im.AddInputField(label, input.Value, 0)
+	}
+	im.AddButton("Submit")
+
+	ui.ConfigureBox(im.Box, &cfg.Theme)
+	ui.UpdateBoxFocus(im.Box, &cfg.Theme, tview.FocusMsg{})
+	im.SetTitle(event.Title)
+	return im
+}
+
+func (im *interactionModal) Update(msg tview.Msg) tview.Cmd {
+	switch msg.(type) {
+	case tview.FormSubmitMsg:
+		return tview.Sequence(im.chat.closeInteractionModal(), im.submit())
+	case tview.FormCancelMsg:
+		return im.chat.closeInteractionModal()
+	}
+	return im.Form.Update(msg)
+}
+
+func (im *interactionModal) submit() tview.Cmd {
+	components := make([]modalRow, len(im.inputs))
+	for i, input := range im.inputs {
+		value := modalValue{
+			Type:     discord.TextInputComponentType,
+			CustomID: input.CustomID,
+			Value:    im.GetFormItem(i).(*tview.InputField).Text(),
+		}
+		if input.labelled {
+			components[i] = modalRow{Type: labelComponent, Component: &value}
+		} else {
+			components[i] = modalRow{Type: discord.ActionRowComponentType, Components: []modalValue{value}}
+		}
+	}
+
+	req := interactionRequest{
+		Type:          discord.ModalInteractionType,
+		ApplicationID: im.event.Application.ID,
+		ChannelID:     im.event.ChannelID,
+		Data: modalSubmitData{
+			ID:         im.event.ID,
+			CustomID:   im.event.CustomID,
+			Components: components,
+		},
+	}
+	if channel, err := im.chat.state.Cabinet.Channel(im.event.ChannelID); err == nil {
+		req.GuildID = channel.GuildID
+	}
+	return im.chat.sendInteraction(req)
+}
+
+func (m *Model) openInteractionModal(event *interactionModalCreateEvent) tview.Cmd {
+	modal := newInteractionModal(m.cfg, m, event)
+	if len(modal.inputs) == 0 {
+		return ui.ShowModal("This form uses inputs that are not supported yet.", ui.ModalButton{Label: "Close"})
+	}
+
+	m.AddLayer(
+		ui.Centered(modal, m.cfg.Picker.Width, promptHeight+2*(len(modal.inputs)-1)),
+		layers.WithName(interactionModalLayerName),
+		layers.WithResize(true),
+		layers.WithVisible(true),
+		layers.WithOverlay(),
+	).SendToFront(interactionModalLayerName)
+	return tview.SetFocus(modal)
+}
+
+func (m *Model) closeInteractionModal() tview.Cmd {
+	m.RemoveLayer(interactionModalLayerName)
+	return tview.SetFocus(m.activeMessagesList())
+}
@@ -29,8 +29,26 @@ func (m *Model) activeKeyMap() help.KeyMap {
 	if m.GetVisible(channelsPickerLayerName) {
 		return m.channelsPicker
 	}
+	if m.GetVisible(searchResultsLayerName) {
+		return m.searchPicker
+	}
+	if m.GetVisible(pinsListLayerName) {
+		return m.pinsList
+	}
+	if m.GetVisible(revisionsLayerName) {
+		return m.revisionsView
+	}
 	if m.GetVisible(attachmentsPickerLayerName) {
-		return m.messagesList.attachmentsPicker
+		return m.activeMessagesList().attachmentsPicker
+	}
+	if m.GetVisible(emojiPickerLayerName) {
+		return m.activeMessagesList().emojiPicker
+	}
+	if m.GetVisible(pollPickerLayerName) {
+		return m.activeMessagesList().pollPicker
+	}
+	if m.GetVisible(componentPickerLayerName) {
+		return m.activeMessagesList().componentPicker
 	}
 
 	switch m.focused {
@@ -40,6 +58,12 @@ func (m *Model) activeKeyMap() help.KeyMap {
 		return m.messagesList
 	case m.composer:
 		return m.composer
+	case m.memberList:
+		return m.memberList
+	case m.threadPane.messagesList:
+		return m.threadPane.messagesList
+	case m.threadPane.composer:
+		return m.threadPane.composer
 	default:
 		return nil
 	}
@@ -48,7 +72,7 @@ func (m *Model) activeKeyMap() help.KeyMap {
 func (m *Model) baseShortHelp() []keybind.Keybind {
 	cfg := m.cfg.Keybinds
 	short := m.focusHelp()
-	short = append(short, cfg.ToggleGuildsTree.Keybind, cfg.ToggleChannelsPicker.Keybind)
+	short = append(short, cfg.ToggleGuildsTree.Keybind, cfg.ToggleChannelsPicker.Keybind, cfg.ToggleSearch.Keybind, cfg.GoToLink.Keybind)
 	return short
 }
 
@@ -57,7 +81,8 @@ func (m *Model) baseFullHelp() [][]keybind.Keybind {
 	return [][]keybind.Keybind{
 		m.focusHelp(),
 		{cfg.FocusPrevious.Keybind, cfg.FocusNext.Keybind},
-		{cfg.ToggleGuildsTree.Keybind, cfg.ToggleChannelsPicker.Keybind},
+		{cfg.ToggleGuildsTree.Keybind, cfg.ToggleChannelsPicker.Keybind, cfg.ToggleSearch.Keybind, cfg.ToggleMemberList.Keybind, cfg.GoToLink.Keybind},
+		{cfg.NextUnread.Keybind, cfg.NextMention.Keybind},
 		{cfg.Logout.Keybind},
 	}
 }
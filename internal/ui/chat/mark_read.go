@@ -0,0 +1,195 @@
+package chat
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/ayn2op/arikawa/v3/api"
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/arikawa/v3/gateway"
+	"github.com/ayn2op/arikawa/v3/utils/httputil"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/tree"
+)
+
+// markReadMsg marks the channels as read.
+type markReadMsg []discord.ChannelID
+
+// markNodeRead marks the channel, guild, folder or DMs under the cursor as
+// read. A category marks the channels in it.
+func (gt *guildsTree) markNodeRead() tview.Cmd {
+	node := gt.CurrentNode()
+	if node == nil {
+		return nil
+	}
+	return gt.markRead(gt.nodeChannels(node))
+}
+
+func (gt *guildsTree) confirmMarkAllRead() tview.Cmd {
+	var channelIDs []discord.ChannelID
+	for _, node := range gt.Root().Children() {
+		channelIDs = append(channelIDs, gt.nodeChannels(node)...)
+	}
+	return ui.ShowModal(
+		"Are you sure you want to mark every channel as read?",
+		ui.ModalButton{Label: "Yes", Result: markReadMsg(channelIDs)},
+		ui.ModalButton{Label: "No"},
+	)
+}
+
+// nodeChannels returns the channels the node holds, or the channel of the node.
+func (gt *guildsTree) nodeChannels(node *tree.Node) []discord.ChannelID {
+	switch ref := node.Reference().(type) {
+	case discord.ChannelID:
+		channel, err := gt.state.Cabinet.Channel(ref)
+		if err != nil {
+			slog.Error("failed to get channel from state", "err", err, "channel_id", ref)
+			return nil
+		}
+		if channel.Type != discord.GuildCategory {
+			return []discord.ChannelID{ref}
+		}
+		return gt.guildChannels(channel.GuildID, ref)
+	case discord.GuildID:
+		return gt.guildChannels(ref, 0)
+	case dmNode:
+		channels, err := gt.state.PrivateChannels()
+		if err != nil {
+			slog.Error("failed to get private channels", "err", err)
+			return nil
+		}
+
+		channelIDs := make([]discord.ChannelID, len(channels))
+		for i, channel := range channels {
+			channelIDs[i] = channel.ID
+		}
+		return channelIDs
+	default: // Folder
+		var channelIDs []discord.ChannelID
+		for _, guildNode := range node.Children() {
+			if guildID, ok := guildNode.Reference().(discord.GuildID); ok {
+				channelIDs = append(channelIDs, gt.guildChannels(guildID, 0)...)
+			}
+		}
+		return channelIDs
+	}
+}
+
+// guildChannels returns the channels of the guild the user can see, only
+// those in the category when its ID is valid.
+func (gt *guildsTree) guildChannels(guildID discord.GuildID, categoryID discord.ChannelID) []discord.ChannelID {
+	channels, err := gt.state.Cabinet.Channels(guildID)
+	if err != nil {
+		slog.Error("failed to get channels from state", "err", err, "guild_id", guildID)
+		return nil
+	}
+
+	var channelIDs []discord.ChannelID
+	for _, channel := range channels {
+		if categoryID.IsValid() && channel.ParentID != categoryID {
+			continue
+		}
+		if channel.Type != discord.GuildCategory && gt.state.HasPermissions(channel.ID, discord.PermissionViewChannel) {
+			channelIDs = append(channelIDs, channel.ID)
+		}
+	}
+	return channelIDs
+}
+
+// maxBulkAcks is the most channels acked in one request.
+const maxBulkAcks = 100
+
+type bulkAck struct {
+	ChannelID     discord.ChannelID `json:"channel_id"`
+	MessageID     discord.MessageID `json:"message_id"`
+	ReadStateType int               `json:"read_state_type"`
+}
+
+// markRead acks the latest message of every unread channel, a batch of
+// channels per request. Every ack is then applied to the read state as if
+// another session had sent it, and onReadUpdate restyles the tree.
+func (gt *guildsTree) markRead(channelIDs []discord.ChannelID) tview.Cmd {
+	return func() tview.Msg {
+		var acks []bulkAck
+		for _, channelID := range channelIDs {
+			lastMessageID := gt.state.LastMessage(channelID)
+			if !lastMessageID.IsValid() {
+				continue
+			}
+			// Skip the channels that are read already to save requests.
+			if readState := gt.state.ReadState.ReadState(channelID); readState != nil && readState.LastMessageID >= lastMessageID {
+				continue
+			}
+			acks = append(acks, bulkAck{ChannelID: channelID, MessageID: lastMessageID})
+		}
+
+		url := api.Endpoint + "read-states/ack-bulk"
+		for batch := range slices.Chunk(acks, maxBulkAcks) {
+			body := struct {
+				ReadStates []bulkAck `json:"read_states"`
+			}{batch}
+			if err := gt.state.FastRequest("POST", url, httputil.WithJSONBody(body)); err != nil {
+				slog.Error("failed to mark channels as read", "err", err, "channels", len(batch))
+				continue
+			}
+			for _, ack := range batch {
+				gt.state.Call(&gateway.MessageAckEvent{ChannelID: ack.ChannelID, MessageID: ack.MessageID})
+			}
+		}
+		return nil
+	}
+}
+
+// markUnread moves the read marker of the channel back so that the selected
+// message is the first unread one. The read state is moved back too once
+// Discord has the ack, which restyles the tree.
+func (ml *messagesList) markUnread() tview.Cmd {
+	selectedChannel, ok := ml.selectedChannel()
+	if !ok {
+		return nil
+	}
+	index := ml.Cursor()
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok {
+		return nil
+	}
+	channelID := selectedMessage.ChannelID
+	// The read marker may point at any snowflake, so the one just before the
+	// selected message marks it unread even when the previous message is not
+	// loaded.
+	lastReadID := selectedMessage.ID - 1
+
+	// Only loaded messages are counted. Every message of a DM counts as a
+	// mention.
+	dm := !selectedChannel.GuildID.IsValid()
+	var mentions int
+	for _, message := range ml.messages[index:] {
+		if ml.chat.isMe(message.Author.ID) {
+			continue
+		}
+		if dm || ml.chat.state.MessageMentions(&message) != 0 {
+			mentions++
+		}
+	}
+
+	// The separator is inserted above the selected message, so the selection
+	// is put back on it.
+	ml.lastReadID = lastReadID
+	ml.rebuildRows()
+	ml.SetCursor(index)
+
+	body := struct {
+		Manual       bool `json:"manual"`
+		MentionCount int  `json:"mention_count"`
+	}{true, mentions}
+	url := api.EndpointChannels + channelID.String() + "/messages/" + lastReadID.String() + "/ack"
+	return func() tview.Msg {
+		if err := ml.chat.state.FastRequest("POST", url, httputil.WithJSONBody(body)); err != nil {
+			slog.Error("failed to mark channel unread", "err", err, "channel_id", channelID, "message_id", lastReadID)
+			return nil
+		}
+		ml.chat.state.ReadState.MarkUnread(channelID, lastReadID, mentions)
+		return nil
+	}
+}
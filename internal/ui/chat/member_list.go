@@ -0,0 +1,477 @@
+package chat
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/arikawa/v3/gateway"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/help"
+	"github.com/ayn2op/tview/keybind"
+	"github.com/ayn2op/tview/list"
+)
+
+// memberListChunk is the number of members in every range of a lazy member
+// list.
+const memberListChunk = 100
+
+// memberListPrefetch is how close to the last loaded row the cursor gets
+// before the next range is requested.
+const memberListPrefetch = 20
+
+var statusLabels = map[discord.Status]string{
+	discord.OnlineStatus:       "Online",
+	discord.IdleStatus:         "Idle",
+	discord.DoNotDisturbStatus: "Do Not Disturb",
+	discord.InvisibleStatus:    "Offline",
+	discord.OfflineStatus:      "Offline",
+}
+
+// memberListRow is either the heading of a group or a member.
+type memberListRow struct {
+	heading string
+
+	user discord.User
+	// member is nil outside guilds.
+	member     *discord.Member
+	status     discord.Status
+	activities []discord.Activity
+}
+
+// memberList is the pane listing the members of the selected channel. Guild
+// channels use the lazy member list Discord keeps up to date, which groups
+// members by hoisted role and then by Online and Offline; DMs list their
+// recipients.
+type memberList struct {
+	*list.Model
+	cfg     *config.Config
+	chat    *Model
+	visible bool
+
+	channel *discord.Channel
+	rows    []memberListRow
+	// loaded is the number of groups and members received for the guild
+	// list, used to request the next range.
+	loaded int
+	// requested holds the ranges of the guild list requested so far.
+	requested map[int]struct{}
+}
+
+var (
+	_ tview.Model = (*memberList)(nil)
+	_ help.KeyMap = (*memberList)(nil)
+)
+
+func newMemberList(cfg *config.Config, chat *Model) *memberList {
+	mem := &memberList{
+		Model:     list.NewModel(),
+		cfg:       cfg,
+		chat:      chat,
+		requested: make(map[int]struct{}),
+	}
+
+	ui.ConfigureBox(mem.Box, &cfg.Theme)
+	mem.SetTitle("Members")
+	mem.SetSelectedStyle(cfg.Theme.MessagesList.SelectedMessageStyle.Style)
+	mem.SetKeybinds(list.Keybinds{
+		SelectUp:     cfg.Keybinds.MemberList.SelectUp.Keybind,
+		SelectDown:   cfg.Keybinds.MemberList.SelectDown.Keybind,
+		SelectTop:    cfg.Keybinds.MemberList.SelectTop.Keybind,
+		SelectBottom: cfg.Keybinds.MemberList.SelectBottom.Keybind,
+	})
+	mem.SetScrollBarVisibility(cfg.Theme.ScrollBar.Visibility.ScrollBarVisibility)
+	mem.SetScrollBar(tview.NewScrollBar().
+		SetTrackStyle(cfg.Theme.ScrollBar.TrackStyle.Style).
+		SetThumbStyle(cfg.Theme.ScrollBar.ThumbStyle.Style).
+		SetGlyphSet(cfg.Theme.ScrollBar.GlyphSet.GlyphSet))
+	return mem
+}
+
+// setChannel lists the members of the channel, subscribing to its member list
+// in guilds.
+func (mem *memberList) setChannel(channel discord.Channel) tview.Cmd {
+	mem.channel = &channel
+	mem.SetTitle("Members of " + ui.ChannelToString(channel, mem.cfg.Icons, mem.chat.state))
+	clear(mem.requested)
+	mem.refresh()
+	mem.SetCursor(-1)
+	if len(mem.rows) > 0 {
+		mem.SetCursor(0)
+	}
+
+	if channel.GuildID.IsValid() {
+		return mem.requestRange(0)
+	}
+	return nil
+}
+
+// refresh rebuilds the rows, keeping the selected member selected when it is
+// still listed, wherever it moved to.
+func (mem *memberList) refresh() {
+	selectedRow, selected := mem.selectedRow()
+	mem.rows = mem.rows[:0]
+	mem.loaded = 0
+	if mem.channel != nil {
+		if mem.channel.GuildID.IsValid() {
+			mem.refreshGuildMembers()
+		} else {
+			mem.refreshRecipients()
+		}
+	}
+
+	mem.SetBuilder(mem.buildItem)
+	if selected {
+		if index := slices.IndexFunc(mem.rows, func(row memberListRow) bool {
+			return row.heading == "" && row.user.ID == selectedRow.user.ID
+		}); index >= 0 {
+			mem.SetCursor(index)
+			return
+		}
+	}
+	if mem.Cursor() >= len(mem.rows) {
+		mem.SetCursor(len(mem.rows) - 1)
+	}
+}
+
+func (mem *memberList) refreshGuildMembers() {
+	guildID := mem.channel.GuildID
+	lazyList, err := mem.chat.state.MemberState.GetMemberList(guildID, mem.channel.ID)
+	if err != nil {
+		// The list arrives after the first range is requested.
+		return
+	}
+
+	lazyList.ViewItems(func(items []gateway.GuildMemberListOpItem) {
+		for _, item := range items {
+			switch {
+			case item.Group != nil:
+				mem.rows = append(mem.rows, memberListRow{heading: mem.groupHeading(guildID, *item.Group)})
+			case item.Member != nil:
+				member := item.Member.Member
+				status := item.Member.Presence.Status
+				if status == "" {
+					status = discord.OfflineStatus
+				}
+				mem.rows = append(mem.rows, memberListRow{
+					user:       member.User,
+					member:     &member,
+					status:     status,
+					activities: item.Member.Presence.Activities,
+				})
+			default:
+				// Not synced yet.
+				continue
+			}
+			mem.loaded++
+		}
+	})
+}
+
+// groupHeading names the group after its hoisted role, or Online or Offline,
+// followed by the number of members in it.
+func (mem *memberList) groupHeading(guildID discord.GuildID, group gateway.GuildMemberListGroup) string {
+	var name string
+	switch group.ID {
+	case "online":
+		name = "Online"
+	case "offline":
+		name = "Offline"
+	default:
+		name = group.ID
+		if id, err := discord.ParseSnowflake(group.ID); err == nil {
+			if role, err := mem.chat.state.Cabinet.Role(guildID, discord.RoleID(id)); err == nil {
+				name = role.Name
+			}
+		}
+	}
+	return name + " — " + strconv.FormatUint(group.Count, 10)
+}
+
+// refreshRecipients lists the recipients of the DM and the user, online ones
+// first.
+func (mem *memberList) refreshRecipients() {
+	users := mem.channel.DMRecipients
+	if me, err := mem.chat.state.Cabinet.Me(); err == nil {
+		users = append(slices.Clone(users), *me)
+	}
+
+	var online, offline []memberListRow
+	for _, user := range users {
+		row := memberListRow{user: user, status: discord.OfflineStatus}
+		if presence, err := mem.chat.state.Cabinet.Presence(discord.NullGuildID, user.ID); err == nil {
+			row.status = presence.Status
+			row.activities = presence.Activities
+		}
+		if row.status == discord.OfflineStatus || row.status == discord.InvisibleStatus {
+			offline = append(offline, row)
+		} else {
+			online = append(online, row)
+		}
+	}
+
+	for _, group := range []struct {
+		name string
+		rows []memberListRow
+	}{{"Online", online}, {"Offline", offline}} {
+		if len(group.rows) == 0 {
+			continue
+		}
+		mem.rows = append(mem.rows, memberListRow{heading: group.name + " — " + strconv.Itoa(len(group.rows))})
+		mem.rows = append(mem.rows, group.rows...)
+	}
+}
+
+func (mem *memberList) buildItem(index int) list.Item {
+	if index < 0 || index >= len(mem.rows) {
+		return nil
+	}
+
+	row := mem.rows[index]
+	if row.heading != "" {
+		return tview.NewTextView().SetLines([]tview.Line{
+			tview.NewLine(tview.NewSegment(row.heading, mem.cfg.Theme.MemberList.GroupStyle.Style)),
+		})
+	}
+
+	lines := []tview.Line{
+		tview.NewLine(tview.NewSegment(memberName(row.user, row.member), mem.chat.guildsTree.dmStatusStyle(row.status))),
+	}
+	if activity := activityString(row.activities); activity != "" {
+		lines = append(lines, tview.NewLine(tview.NewSegment("  "+activity, mem.cfg.Theme.MemberList.ActivityStyle.Style)))
+	}
+	return tview.NewTextView().SetWrap(false).SetLines(lines)
+}
+
+// memberName returns the name the user goes by in the guild of the member, if
+// any.
+func memberName(user discord.User, member *discord.Member) string {
+	if member != nil && member.Nick != "" {
+		return member.Nick
+	}
+	return user.DisplayOrUsername()
+}
+
+// activityString describes the first activity, e.g. "Playing Chess" or the
+// text of a custom status.
+func activityString(activities []discord.Activity) string {
+	if len(activities) == 0 {
+		return ""
+	}
+
+	activity := activities[0]
+	switch activity.Type {
+	case discord.GameActivity:
+		return "Playing " + activity.Name
+	case discord.StreamingActivity:
+		return "Streaming " + activity.Name
+	case discord.ListeningActivity:
+		return "Listening to " + activity.Name
+	case discord.WatchingActivity:
+		return "Watching " + activity.Name
+	case discord.CustomActivity:
+		return activity.State
+	case discord.CompetingActivity:
+		return "Competing in " + activity.Name
+	default:
+		return activity.Name
+	}
+}
+
+// requestRange subscribes to the given range of members of the guild list.
+// Every range is requested once per channel.
+func (mem *memberList) requestRange(chunk int) tview.Cmd {
+	if mem.channel == nil || !mem.channel.GuildID.IsValid() {
+		return nil
+	}
+	if _, ok := mem.requested[chunk]; ok {
+		return nil
+	}
+	mem.requested[chunk] = struct{}{}
+
+	guildID, channelID := mem.channel.GuildID, mem.channel.ID
+	return func() tview.Msg {
+		mem.chat.state.MemberState.RequestMemberList(guildID, channelID, chunk)
+		return nil
+	}
+}
+
+// onMemberListUpdate redraws the list when Discord updates the member list of
+// the guild.
+func (mem *memberList) onMemberListUpdate(event *gateway.GuildMemberListUpdate) {
+	if !mem.visible || mem.channel == nil || mem.channel.GuildID != event.GuildID {
+		return
+	}
+	mem.refresh()
+}
+
+// onPresenceUpdate redraws the recipients of a DM when one of them changes
+// status. Guild lists receive presences with their updates.
+func (mem *memberList) onPresenceUpdate(event *gateway.PresenceUpdateEvent) {
+	if !mem.visible || mem.channel == nil || mem.channel.GuildID.IsValid() {
+		return
+	}
+	for _, user := range mem.channel.DMRecipients {
+		if user.ID == event.User.ID {
+			mem.refresh()
+			return
+		}
+	}
+}
+
+func (mem *memberList) selectedRow() (memberListRow, bool) {
+	index := mem.Cursor()
+	if index < 0 || index >= len(mem.rows) || mem.rows[index].heading != "" {
+		return memberListRow{}, false
+	}
+	return mem.rows[index], true
+}
+
+func (mem *memberList) Update(msg tview.Msg) tview.Cmd {
+	ui.UpdateBoxFocus(mem.Box, &mem.cfg.Theme, msg)
+	switch msg := msg.(type) {
+	case tview.FocusMsg:
+		return tview.Sequence(mem.Model.Update(msg), focused(mem))
+	case tview.KeyMsg:
+		switch {
+		case keybind.Matches(msg, mem.cfg.Keybinds.MemberList.SelectCurrent.Keybind):
+			return mem.showProfile()
+		case keybind.Matches(msg, mem.cfg.Keybinds.MemberList.Mention.Keybind):
+			if row, ok := mem.selectedRow(); ok {
+				return mem.chat.mentionUser(row.user)
+			}
+			return nil
+		case keybind.Matches(msg, mem.cfg.Keybinds.MemberList.DirectMessage.Keybind):
+			if row, ok := mem.selectedRow(); ok {
+				return mem.chat.openDirectMessage(row.user.ID)
+			}
+			return nil
+		}
+
+		cmd := mem.Model.Update(msg)
+		// Load the next range of the guild list before the cursor reaches
+		// its end.
+		if mem.Cursor() >= len(mem.rows)-memberListPrefetch {
+			return tview.Batch(cmd, mem.requestRange(mem.loaded/memberListChunk))
+		}
+		return cmd
+	}
+	return mem.Model.Update(msg)
+}
+
+// showProfile shows the selected member with the actions on them.
+func (mem *memberList) showProfile() tview.Cmd {
+	row, ok := mem.selectedRow()
+	if !ok {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString(memberName(row.user, row.member))
+	if row.user.Username != "" {
+		b.WriteString(" (" + row.user.Username + ")")
+	}
+	b.WriteString("\n" + statusLabels[row.status])
+	if activity := activityString(row.activities); activity != "" {
+		b.WriteString(" · " + activity)
+	}
+
+	if row.member != nil && mem.channel != nil {
+		var roles []string
+		for _, roleID := range row.member.RoleIDs {
+			role, err := mem.chat.state.Cabinet.Role(mem.channel.GuildID, roleID)
+			if err != nil {
+				slog.Error("failed to get role from state", "err", err, "guild_id", mem.channel.GuildID, "role_id", roleID)
+				continue
+			}
+			roles = append(roles, role.Name)
+		}
+		if len(roles) > 0 {
+			b.WriteString("\nRoles: " + strings.Join(roles, ", "))
+		}
+		if row.member.Joined.IsValid() {
+			b.WriteString("\nJoined " + row.member.Joined.Time().Local().Format("January 2, 2006"))
+		}
+	}
+	b.WriteString("\nCreated " + row.user.ID.Time().Local().Format("January 2, 2006"))
+
+	return ui.ShowModal(
+		b.String(),
+		ui.ModalButton{Label: "Mention", Result: mentionUserMsg(row.user)},
+		ui.ModalButton{Label: "Message", Result: directMessageMsg(row.user.ID)},
+		ui.ModalButton{Label: "Close"},
+	)
+}
+
+func (mem *memberList) ShortHelp() []keybind.Keybind {
+	cfg := mem.cfg.Keybinds.MemberList
+	return []keybind.Keybind{cfg.SelectUp.Keybind, cfg.SelectDown.Keybind, cfg.SelectCurrent.Keybind, cfg.Mention.Keybind, cfg.DirectMessage.Keybind}
+}
+
+func (mem *memberList) FullHelp() [][]keybind.Keybind {
+	cfg := mem.cfg.Keybinds.MemberList
+	return [][]keybind.Keybind{
+		{cfg.SelectUp.Keybind, cfg.SelectDown.Keybind, cfg.SelectTop.Keybind, cfg.SelectBottom.Keybind},
+		{cfg.SelectCurrent.Keybind, cfg.Mention.Keybind, cfg.DirectMessage.Keybind},
+	}
+}
+
+// mentionUser adds a mention of the user to the composer.
+func (m *Model) mentionUser(user discord.User) tview.Cmd {
+	if m.composer.Disabled() {
+		return nil
+	}
+
+	text := m.composer.Text()
+	if text != "" && !strings.HasSuffix(text, " ") {
+		text += " "
+	}
+	m.composer.SetText(text+user.ID.Mention()+" ", true)
+	return tview.SetFocus(m.composer)
+}
+
+// openDirectMessage opens the DM with the user, creating it if needed.
+func (m *Model) openDirectMessage(userID discord.UserID) tview.Cmd {
+	return func() tview.Msg {
+		channel, err := m.state.CreatePrivateChannel(userID)
+		if err != nil {
+			slog.Error("failed to create private channel", "err", err, "user_id", userID)
+			return nil
+		}
+		return directMessageOpenedMsg(*channel)
+	}
+}
+
+// onDirectMessageOpened selects the DM in the guilds tree. A DM created after
+// the tree listed the DMs has no node yet.
+func (m *Model) onDirectMessageOpened(channel discord.Channel) tview.Cmd {
+	gt := m.guildsTree
+	if gt.findNodeByChannelID(channel.ID) == nil && gt.dmRootNode != nil {
+		gt.createChannelNode(gt.dmRootNode, channel)
+	}
+	return m.navigateToChannel(channel.ID)
+}
+
+func (m *Model) toggleMemberList() tview.Cmd {
+	mem := m.memberList
+	if mem.visible {
+		mem.visible = false
+		m.mainFlex.RemoveItem(mem)
+		if mem.HasFocus() {
+			return tview.SetFocus(m.messagesList)
+		}
+		return nil
+	}
+
+	mem.visible = true
+	m.mainFlex.AddItem(mem, 0, m.cfg.MemberList.WidthPercent, false)
+	var cmd tview.Cmd
+	if selectedChannel, ok := m.SelectedChannel(); ok {
+		cmd = mem.setChannel(*selectedChannel)
+	}
+	return tview.Batch(cmd, tview.SetFocus(mem))
+}
@@ -0,0 +1,73 @@
+package chat
+
+import (
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/tview"
+	"github.com/gdamore/tcell/v3"
+)
+
+// cozyIndent indents message bodies below their group's header.
+const cozyIndent = "  "
+
+// messageItem is a rendered message, cached together with whether it was
+// rendered as part of a group.
+type messageItem struct {
+	*tview.TextView
+	grouped bool
+}
+
+// isUserMessage reports whether messages of the type are written by users,
+// as opposed to system messages, and so take part in cozy groups.
+func isUserMessage(messageType discord.MessageType) bool {
+	switch messageType {
+	case discord.DefaultMessage, discord.InlinedReplyMessage, discord.ChatInputCommandMessage, discord.ContextMenuCommand, discord.InteractionPremiumUpsellMessage, discord.ThreadStarterMessage:
+		return true
+	}
+	return false
+}
+
+// continuesGroup reports whether, in cozy mode, message is drawn under the
+// header of prev. Replies always start a new group so the replied-to message
+// is shown next to the author.
+func (ml *messagesList) continuesGroup(prev, message discord.Message) bool {
+	if ml.cfg.MessagesList.Display != config.DisplayCozy {
+		return false
+	}
+	if !isUserMessage(prev.Type) || message.Type != discord.DefaultMessage {
+		return false
+	}
+	// Webhooks post under many names with the same ID.
+	if prev.Author.ID != message.Author.ID || prev.Author.Username != message.Author.Username {
+		return false
+	}
+	elapsed := message.Timestamp.Time().Sub(prev.Timestamp.Time())
+	return elapsed >= 0 && elapsed <= ml.cfg.MessagesList.GroupWindow
+}
+
+func (ml *messagesList) drawCozyHeader(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
+	name, style := ml.authorName(message, baseStyle)
+	builder.Write(name, style)
+	if ml.cfg.Timestamps.Enabled {
+		builder.Write(" "+ml.formatTimestamp(message.Timestamp), baseStyle.Dim(true))
+	}
+}
+
+// indentLines wraps the lines to the body width and indents them.
+func (ml *messagesList) indentLines(lines []tview.Line, baseStyle tcell.Style) []tview.Line {
+	indented := make([]tview.Line, 0, len(lines))
+	width := ml.bodyWidth()
+	for _, line := range lines {
+		for _, wrapped := range wrapStyledLine(line, width) {
+			indented = append(indented, append(tview.Line{tview.NewSegment(cozyIndent, baseStyle)}, wrapped...))
+		}
+	}
+	return indented
+}
+
+// bodyWidth returns the width available to the message body, which is
+// narrower than the list when the body is indented.
+func (ml *messagesList) bodyWidth() int {
+	_, _, width, _ := ml.InnerRect()
+	return width - ml.indent
+}
@@ -0,0 +1,102 @@
+package chat
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+)
+
+var errInvalidMessageLink = errors.New("not a Discord message link")
+
+// messageLink is a parsed https://discord.com/channels/<guild>/<channel>/<message>
+// link. GuildID is zero for direct messages (@me) and MessageID is zero for
+// links to a channel.
+type messageLink struct {
+	GuildID   discord.GuildID
+	ChannelID discord.ChannelID
+	MessageID discord.MessageID
+}
+
+func parseMessageLink(rawURL string) (messageLink, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return messageLink{}, errInvalidMessageLink
+	}
+
+	switch strings.TrimPrefix(u.Host, "www.") {
+	case "discord.com", "ptb.discord.com", "canary.discord.com", "discordapp.com":
+	default:
+		return messageLink{}, errInvalidMessageLink
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 || len(parts) > 4 || parts[0] != "channels" {
+		return messageLink{}, errInvalidMessageLink
+	}
+
+	var link messageLink
+	if parts[1] != "@me" {
+		guildID, err := discord.ParseSnowflake(parts[1])
+		if err != nil {
+			return messageLink{}, errInvalidMessageLink
+		}
+		link.GuildID = discord.GuildID(guildID)
+	}
+
+	channelID, err := discord.ParseSnowflake(parts[2])
+	if err != nil {
+		return messageLink{}, errInvalidMessageLink
+	}
+	link.ChannelID = discord.ChannelID(channelID)
+
+	if len(parts) == 4 {
+		messageID, err := discord.ParseSnowflake(parts[3])
+		if err != nil {
+			return messageLink{}, errInvalidMessageLink
+		}
+		link.MessageID = discord.MessageID(messageID)
+	}
+
+	return link, nil
+}
+
+// openLink navigates to Discord message links inside discordo and hands every
+// other URL to the browser.
+func openLink(rawURL string) tview.Cmd {
+	link, err := parseMessageLink(rawURL)
+	if err != nil {
+		return openURL(rawURL)
+	}
+	return func() tview.Msg { return messageLinkMsg{Link: link} }
+}
+
+func (m *Model) toggleLinkPrompt() tview.Cmd {
+	if m.HasLayer(promptLayerName) {
+		return m.closePrompt()
+	}
+
+	return m.openPrompt("Go to link", "Link", func(text string) tview.Cmd {
+		link, err := parseMessageLink(text)
+		if err != nil {
+			return ui.ShowModal(err.Error(), ui.ModalButton{Label: "Close"})
+		}
+		return m.goToLink(link)
+	})
+}
+
+// goToLink opens the channel of a message link and, when the link points at a
+// message, loads and highlights it.
+func (m *Model) goToLink(link messageLink) tview.Cmd {
+	if _, err := m.state.Cabinet.Channel(link.ChannelID); err != nil {
+		return ui.ShowModal("You do not have access to this channel.", ui.ModalButton{Label: "Close"})
+	}
+
+	if !link.MessageID.IsValid() {
+		return m.navigateToChannel(link.ChannelID)
+	}
+	return m.navigateToMessage(link.ChannelID, link.MessageID)
+}
@@ -0,0 +1,52 @@
+package chat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMessageLink(t *testing.T) {
+	tests := []struct {
+		rawURL string
+		want   messageLink
+	}{
+		{"https://discord.com/channels/1/2/3", messageLink{GuildID: 1, ChannelID: 2, MessageID: 3}},
+		{"https://ptb.discord.com/channels/1/2/3", messageLink{GuildID: 1, ChannelID: 2, MessageID: 3}},
+		{"https://canary.discord.com/channels/1/2/3", messageLink{GuildID: 1, ChannelID: 2, MessageID: 3}},
+		{"https://discordapp.com/channels/1/2/3", messageLink{GuildID: 1, ChannelID: 2, MessageID: 3}},
+		{"https://www.discord.com/channels/1/2/3", messageLink{GuildID: 1, ChannelID: 2, MessageID: 3}},
+		{"https://discord.com/channels/@me/2/3", messageLink{ChannelID: 2, MessageID: 3}},
+		{"https://discord.com/channels/1/2", messageLink{GuildID: 1, ChannelID: 2}},
+		{"https://discord.com/channels/1/2/3/", messageLink{GuildID: 1, ChannelID: 2, MessageID: 3}},
+		{"  https://discord.com/channels/1/2/3\n", messageLink{GuildID: 1, ChannelID: 2, MessageID: 3}},
+	}
+
+	for _, test := range tests {
+		got, err := parseMessageLink(test.rawURL)
+		if err != nil {
+			t.Fatalf("parseMessageLink(%q): %v", test.rawURL, err)
+		}
+		if got != test.want {
+			t.Errorf("parseMessageLink(%q) = %+v, want %+v", test.rawURL, got, test.want)
+		}
+	}
+}
+
+func TestParseMessageLinkErrors(t *testing.T) {
+	for _, rawURL := range []string{
+		"",
+		"https://discord.com/channels/1",
+		"https://discord.com/channels/@me",
+		"https://discord.com/channels/1/2/3/4",
+		"https://discord.com/guilds/1/2/3",
+		"https://discord.com/channels/one/2/3",
+		"https://discord.com/channels/1/two/3",
+		"https://discord.com/channels/1/2/three",
+		"https://example.com/channels/1/2/3",
+		"https://discord.com.example.com/channels/1/2/3",
+	} {
+		if _, err := parseMessageLink(rawURL); !errors.Is(err, errInvalidMessageLink) {
+			t.Errorf("parseMessageLink(%q) = %v, want %v", rawURL, err, errInvalidMessageLink)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package chat
 
 import (
+	"cmp"
 	"context"
 	"io"
 	"log/slog"
@@ -26,6 +27,9 @@ import (
 	"github.com/ayn2op/discordo/internal/markdown"
 	"github.com/ayn2op/discordo/internal/ui"
 	"github.com/ayn2op/discordo/internal/ui/chat/attachmentspicker"
+	"github.com/ayn2op/discordo/internal/ui/chat/componentpicker"
+	"github.com/ayn2op/discordo/internal/ui/chat/emojipicker"
+	"github.com/ayn2op/discordo/internal/ui/chat/pollpicker"
 	"github.com/ayn2op/ningen/v3/discordmd"
 	"github.com/ayn2op/tview"
 	"github.com/ayn2op/tview/help"
@@ -50,9 +54,56 @@ type messagesList struct {
 
 	renderer *markdown.Renderer
 	// itemByID caches rendered message TextViews.
-	itemByID map[discord.MessageID]*tview.TextView
+	itemByID map[discord.MessageID]messageItem
+	// componentsByID caches the parsed components of messages.
+	componentsByID map[discord.MessageID][]component
+	// renderWidth is the inner width the cached items were rendered for.
+	renderWidth int
+	// indent is the width of the indentation of the message body being
+	// rendered; see bodyWidth.
+	indent int
+
+	// lastReadID is the channel's read marker captured before the channel was
+	// marked as read on load. Messages newer than it are drawn below the
+	// "New messages" separator.
+	lastReadID discord.MessageID
+	// detached is set when the loaded messages are a window of older history
+	// that does not reach the latest message of the channel. Selecting past
+	// the bottom then fetches newer pages.
+	detached bool
+	// pending buffers messages created while detached. They are appended once
+	// the window meets the live tail.
+	pending []discord.Message
+	// highlightedID is the message that was navigated to through a link or a
+	// search result.
+	highlightedID discord.MessageID
+	// marked holds the messages marked one by one. visualAnchorID is the
+	// message visual mode was started on and visualEndID the one the range
+	// ends on; both are zero outside visual mode.
+	marked         map[discord.MessageID]struct{}
+	visualAnchorID discord.MessageID
+	visualEndID    discord.MessageID
+
+	// composer replies to, edits and sends messages in the list's channel.
+	composer *composer
+	// thread is the thread shown by the thread pane's list. It is nil for the
+	// main list, which follows the selected channel.
+	thread *discord.Channel
 
 	attachmentsPicker *attachmentspicker.Model
+
+	emojiPicker *emojipicker.Model
+	// reactionMessageID is the message the emoji picker was opened for.
+	reactionMessageID discord.MessageID
+
+	pollPicker *pollpicker.Model
+	// pollMessageID is the message the poll picker was opened for.
+	pollMessageID discord.MessageID
+
+	componentPicker *componentpicker.Model
+	// selectMenu is the select menu whose options the component picker
+	// lists, if any.
+	selectMenu *selectMenu
 }
 
 var _ help.KeyMap = (*messagesList)(nil)
@@ -62,23 +113,39 @@ type messagesListRowKind uint8
 const (
 	messagesListRowMessage messagesListRowKind = iota
 	messagesListRowSeparator
+	messagesListRowUnread
+)
+
+const (
+	unreadSeparatorLabel = "New messages"
+	// maxUnreadPages caps how many older pages jumping to the first unread
+	// message fetches before giving up.
+	maxUnreadPages = 10
 )
 
 type messagesListRow struct {
 	kind         messagesListRowKind
 	messageIndex int
 	timestamp    discord.Timestamp
+	// grouped is set in cozy mode for messages drawn without a header
+	// because they continue the previous message's group.
+	grouped bool
 }
 
 func newMessagesList(cfg *config.Config, chat *Model) *messagesList {
 	ml := &messagesList{
-		Model:    list.NewModel(),
-		cfg:      cfg,
-		chat:     chat,
-		renderer: markdown.NewRenderer(cfg),
-		itemByID: make(map[discord.MessageID]*tview.TextView),
+		Model:          list.NewModel(),
+		cfg:            cfg,
+		chat:           chat,
+		renderer:       markdown.NewRenderer(cfg),
+		itemByID:       make(map[discord.MessageID]messageItem),
+		componentsByID: make(map[discord.MessageID][]component),
+		marked:         make(map[discord.MessageID]struct{}),
 	}
 	ml.attachmentsPicker = attachmentspicker.NewModel(cfg)
+	ml.emojiPicker = emojipicker.NewModel(cfg)
+	ml.pollPicker = pollpicker.NewModel(cfg)
+	ml.componentPicker = componentpicker.NewModel(cfg)
 
 	ui.ConfigureBox(ml.Box, &cfg.Theme)
 	ml.SetTitle("Messages")
@@ -102,13 +169,28 @@ func newMessagesList(cfg *config.Config, chat *Model) *messagesList {
 func (ml *messagesList) reset() {
 	ml.messages = nil
 	ml.rows = nil
+	ml.lastReadID = 0
+	ml.detached = false
+	ml.pending = nil
+	ml.highlightedID = 0
+	clear(ml.marked)
+	ml.visualAnchorID = 0
+	ml.visualEndID = 0
 	clear(ml.itemByID)
+	clear(ml.componentsByID)
 	ml.
 		Clear().
 		SetBuilder(ml.buildItem).
 		SetTitle("")
 }
 
+func (ml *messagesList) selectedChannel() (*discord.Channel, bool) {
+	if ml.thread != nil {
+		return ml.thread, true
+	}
+	return ml.chat.SelectedChannel()
+}
+
 func (ml *messagesList) setTitle(channel discord.Channel) {
 	title := ui.ChannelToString(channel, ml.cfg.Icons, ml.chat.state)
 	if topic := channel.Topic; topic != "" {
@@ -122,12 +204,14 @@ func (ml *messagesList) setMessages(messages []discord.Message) {
 	ml.messages = slices.Clone(messages)
 	slices.Reverse(ml.messages)
 	clear(ml.itemByID)
+	clear(ml.componentsByID)
 	ml.rebuildRows()
 }
 
 func (ml *messagesList) addMessage(message discord.Message) {
 	ml.messages = append(ml.messages, message)
 	delete(ml.itemByID, message.ID)
+	delete(ml.componentsByID, message.ID)
 	ml.rebuildRows()
 }
 
@@ -138,6 +222,7 @@ func (ml *messagesList) setMessage(index int, message discord.Message) {
 
 	ml.messages[index] = message
 	delete(ml.itemByID, message.ID)
+	delete(ml.componentsByID, message.ID)
 	ml.rebuildRows()
 }
 
@@ -147,6 +232,7 @@ func (ml *messagesList) deleteMessage(index int) {
 	}
 
 	delete(ml.itemByID, ml.messages[index].ID)
+	delete(ml.componentsByID, ml.messages[index].ID)
 	ml.messages = slices.Delete(ml.messages, index, index+1)
 	ml.rebuildRows()
 }
@@ -161,34 +247,87 @@ func (ml *messagesList) buildItem(index int) list.Item {
 	}
 
 	row := ml.rows[index]
-	if row.kind == messagesListRowSeparator {
-		return ml.buildSeparatorItem(row.timestamp)
+	if row.kind != messagesListRowMessage {
+		return ml.buildSeparatorItem(row)
 	}
 
 	// The list applies the selection style at draw time, so a message is
 	// rendered once and reused for every cursor position. Cursor moves no
 	// longer re-parse markdown or re-run syntax highlighting.
 	message := ml.messages[row.messageIndex]
+	// A message is rendered again when it joins or leaves a group, e.g. when
+	// the message above it is deleted.
 	item, ok := ml.itemByID[message.ID]
-	if !ok {
-		item = tview.NewTextView().
-			SetWrap(true).
-			SetWordWrap(true).
-			SetLines(ml.renderMessage(message, ml.cfg.Theme.MessagesList.MessageStyle.Style))
+	if !ok || item.grouped != row.grouped {
+		baseStyle := ml.cfg.Theme.MessagesList.MessageStyle.Style
+		if message.ID == ml.highlightedID {
+			baseStyle = tview.MergeStyle(baseStyle, ml.cfg.Theme.MessagesList.HighlightedMessageStyle.Style)
+		}
+		if ml.chat.audit.isDeleted(message.ID) {
+			baseStyle = tview.MergeStyle(baseStyle, ml.cfg.Theme.MessagesList.DeletedMessageStyle.Style)
+		}
+		if ml.isMarked(row.messageIndex) {
+			baseStyle = tview.MergeStyle(baseStyle, ml.cfg.Theme.MessagesList.MarkedMessageStyle.Style)
+		}
+		item = messageItem{
+			TextView: tview.NewTextView().
+				SetWrap(true).
+				SetWordWrap(true).
+				SetLines(ml.renderGroupedMessage(message, baseStyle, row.grouped)),
+			grouped: row.grouped,
+		}
 		ml.itemByID[message.ID] = item
 	}
-	return item
+	return item.TextView
+}
+
+// View drops the cached items when the list is resized, since embeds and
+// image previews are sized to its width.
+func (ml *messagesList) View(screen tcell.Screen) {
+	if _, _, width, _ := ml.InnerRect(); width != ml.renderWidth {
+		ml.renderWidth = width
+		clear(ml.itemByID)
+	}
+	ml.Model.View(screen)
 }
 
 func (ml *messagesList) renderMessage(message discord.Message, baseStyle tcell.Style) []tview.Line {
+	return ml.renderGroupedMessage(message, baseStyle, false)
+}
+
+// renderGroupedMessage renders a message; in cozy mode, grouped messages are
+// drawn without the author header.
+func (ml *messagesList) renderGroupedMessage(message discord.Message, baseStyle tcell.Style, grouped bool) []tview.Line {
 	builder := tview.NewLineBuilder()
-	ml.writeMessage(builder, message, baseStyle)
+	if ml.cfg.MessagesList.Display != config.DisplayCozy || !isUserMessage(message.Type) {
+		ml.writeMessage(builder, message, baseStyle)
+		return builder.Finish()
+	}
+
+	body := tview.NewLineBuilder()
+	ml.indent = uniseg.StringWidth(cozyIndent)
+	ml.writeMessage(body, message, baseStyle)
+	ml.indent = 0
+
+	if !grouped {
+		ml.drawCozyHeader(builder, message, baseStyle)
+		builder.NewLine()
+	}
+	builder.AppendLines(ml.indentLines(body.Finish(), baseStyle))
 	return builder.Finish()
 }
 
-func (ml *messagesList) buildSeparatorItem(ts discord.Timestamp) *tview.TextView {
+func (ml *messagesList) buildSeparatorItem(row messagesListRow) *tview.TextView {
 	builder := tview.NewLineBuilder()
-	ml.drawDateSeparator(builder, ts, ml.cfg.Theme.MessagesList.MessageStyle.Style)
+	baseStyle := ml.cfg.Theme.MessagesList.MessageStyle.Style
+	switch row.kind {
+	case messagesListRowUnread:
+		style := tview.MergeStyle(baseStyle, ml.cfg.Theme.MessagesList.UnreadSeparatorStyle.Style)
+		ml.drawSeparator(builder, unreadSeparatorLabel, style)
+	default:
+		date := row.timestamp.Time().In(time.Local).Format(ml.cfg.DateSeparator.Format)
+		ml.drawSeparator(builder, date, baseStyle.Dim(true))
+	}
 	return tview.NewTextView().
 		SetScrollable(false).
 		SetWrap(false).
@@ -196,33 +335,36 @@ func (ml *messagesList) buildSeparatorItem(ts discord.Timestamp) *tview.TextView
 		SetLines(builder.Finish())
 }
 
-func (ml *messagesList) drawDateSeparator(builder *tview.LineBuilder, ts discord.Timestamp, baseStyle tcell.Style) {
-	date := ts.Time().In(time.Local).Format(ml.cfg.DateSeparator.Format)
-	label := " " + date + " "
+func (ml *messagesList) drawSeparator(builder *tview.LineBuilder, text string, style tcell.Style) {
+	label := " " + text + " "
 	fillChar := ml.cfg.DateSeparator.Character
-	dimStyle := baseStyle.Dim(true)
 	_, _, width, _ := ml.InnerRect()
 	if width <= 0 {
-		builder.Write(strings.Repeat(fillChar, 8)+label+strings.Repeat(fillChar, 8), dimStyle)
+		builder.Write(strings.Repeat(fillChar, 8)+label+strings.Repeat(fillChar, 8), style)
 		return
 	}
 
 	labelWidth := utf8.RuneCountInString(label)
 	if width <= labelWidth {
-		builder.Write(date, dimStyle)
+		builder.Write(text, style)
 		return
 	}
 
 	fillWidth := width - labelWidth
 	left := fillWidth / 2
 	right := fillWidth - left
-	builder.Write(strings.Repeat(fillChar, left)+label+strings.Repeat(fillChar, right), dimStyle)
+	builder.Write(strings.Repeat(fillChar, left)+label+strings.Repeat(fillChar, right), style)
 }
 
 func (ml *messagesList) rebuildRows() {
 	rows := make([]messagesListRow, 0, len(ml.messages)*2)
+	unreadIndex := ml.firstUnreadIndex()
 
 	for index := range ml.messages {
+		if index == unreadIndex {
+			rows = append(rows, messagesListRow{kind: messagesListRowUnread})
+		}
+
 		// Always show a date separator before the first message, and between messages on different days.
 		if ml.cfg.DateSeparator.Enabled && (index == 0 || !sameLocalDate(ml.messages[index-1].Timestamp, ml.messages[index].Timestamp)) {
 			rows = append(rows, messagesListRow{
@@ -231,9 +373,13 @@ func (ml *messagesList) rebuildRows() {
 			})
 		}
 
+		// Separators end groups.
+		grouped := index > 0 && len(rows) > 0 && rows[len(rows)-1].kind == messagesListRowMessage &&
+			ml.continuesGroup(ml.messages[index-1], ml.messages[index])
 		rows = append(rows, messagesListRow{
 			kind:         messagesListRowMessage,
 			messageIndex: index,
+			grouped:      grouped,
 		})
 	}
 
@@ -241,6 +387,23 @@ func (ml *messagesList) rebuildRows() {
 	ml.SetBuilder(ml.buildItem)
 }
 
+// firstUnreadIndex returns the index of the oldest loaded message newer than
+// the read marker. It returns -1 if there is no read marker, if every loaded
+// message has been read, or if the marker lies before the loaded window.
+func (ml *messagesList) firstUnreadIndex() int {
+	if !ml.lastReadID.IsValid() {
+		return -1
+	}
+
+	index := slices.IndexFunc(ml.messages, func(m discord.Message) bool {
+		return m.ID > ml.lastReadID
+	})
+	if index <= 0 {
+		return -1
+	}
+	return index
+}
+
 func sameLocalDate(a discord.Timestamp, b discord.Timestamp) bool {
 	ta := a.Time().In(time.Local)
 	tb := b.Time().In(time.Local)
@@ -314,25 +477,21 @@ func (ml *messagesList) writeMessage(builder *tview.LineBuilder, message discord
 	}
 
 	switch message.Type {
-	case discord.DefaultMessage:
+	case discord.DefaultMessage, discord.ChatInputCommandMessage, discord.ContextMenuCommand, discord.InteractionPremiumUpsellMessage:
 		if message.Reference != nil && message.Reference.Type == discord.MessageReferenceTypeForward {
 			ml.drawForwardedMessage(builder, message, baseStyle)
 		} else {
 			ml.drawDefaultMessage(builder, message, baseStyle)
 		}
-	case discord.GuildMemberJoinMessage:
-		ml.drawTimestamps(builder, message.Timestamp, baseStyle)
-		ml.drawAuthor(builder, message, baseStyle)
-		builder.Write("joined the server.", baseStyle)
 	case discord.InlinedReplyMessage:
 		ml.drawReplyMessage(builder, message, baseStyle)
-	case discord.ChannelPinnedMessage:
-		ml.drawPinnedMessage(builder, message, baseStyle)
+	case discord.ThreadStarterMessage:
+		ml.drawThreadStarterMessage(builder, message, baseStyle)
 	default:
-		ml.drawTimestamps(builder, message.Timestamp, baseStyle)
-		ml.drawAuthor(builder, message, baseStyle)
+		ml.drawSystemMessage(builder, message, baseStyle)
 	}
 	ml.drawReactions(builder, message.Reactions, baseStyle)
+	ml.drawThread(builder, message, baseStyle)
 }
 
 func (ml *messagesList) drawReactions(builder *tview.LineBuilder, reactions []discord.Reaction, baseStyle tcell.Style) {
@@ -367,7 +526,34 @@ func (ml *messagesList) drawTimestamps(builder *tview.LineBuilder, ts discord.Ti
 	builder.Write(ml.formatTimestamp(ts)+" ", dimStyle)
 }
 
+// drawHeader writes the timestamp and author in front of the message body.
+// In cozy mode the header is drawn above the body instead.
+func (ml *messagesList) drawHeader(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
+	switch ml.cfg.MessagesList.Display {
+	case config.DisplayCozy:
+		return
+	case config.DisplayIRC:
+		if ml.cfg.Timestamps.Enabled {
+			ml.drawTimestamps(builder, message.Timestamp, baseStyle)
+		}
+		name, style := ml.authorName(message, baseStyle)
+		builder.Write("<"+name+"> ", style)
+	default:
+		if ml.cfg.Timestamps.Enabled {
+			ml.drawTimestamps(builder, message.Timestamp, baseStyle)
+		}
+		ml.drawAuthor(builder, message, baseStyle)
+	}
+}
+
 func (ml *messagesList) drawAuthor(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
+	name, style := ml.authorName(message, baseStyle)
+	builder.Write(name+" ", style)
+}
+
+// authorName returns the name the author goes by in the channel and the style
+// of their top role's colour.
+func (ml *messagesList) authorName(message discord.Message, baseStyle tcell.Style) (string, tcell.Style) {
 	name := message.Author.DisplayOrUsername()
 	foreground := tcell.ColorDefault
 
@@ -385,8 +571,7 @@ func (ml *messagesList) drawAuthor(builder *tview.LineBuilder, message discord.M
 		}
 	}
 
-	style := baseStyle.Foreground(foreground).Bold(true)
-	builder.Write(name+" ", style)
+	return name, baseStyle.Foreground(foreground).Bold(true)
 }
 
 func (ml *messagesList) memberForMessage(message discord.Message) *discord.Member {
@@ -440,8 +625,10 @@ func (ml *messagesList) renderContentLines(message discord.Message, baseStyle tc
 func (ml *messagesList) renderContentLinesWithMarkdown(message discord.Message, baseStyle tcell.Style, forceMarkdown bool) ([]tview.Line, ast.Node, []byte) {
 	// Keep one rendering path for both normal messages and embed fragments so we preserve mention/link parsing behavior consistently across both.
 	if forceMarkdown || ml.cfg.Markdown.Enabled {
-		c := []byte(message.Content)
+		c := markdown.ExpandQuotes([]byte(message.Content))
 		root := discordmd.ParseWithMessage(c, *ml.chat.state.Cabinet, &message, false)
+		markdown.Transform(root, c)
+		ml.renderer.SetMaskSpoilers(ml.chat.spoilersMasked(message.ID))
 		return ml.renderer.RenderLines(c, root, baseStyle), root, c
 	}
 
@@ -471,23 +658,30 @@ func (ml *messagesList) drawSnapshotContent(builder *tview.LineBuilder, parent d
 }
 
 func (ml *messagesList) drawDefaultMessage(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
-	if ml.cfg.Timestamps.Enabled {
-		ml.drawTimestamps(builder, message.Timestamp, baseStyle)
-	}
-
-	ml.drawAuthor(builder, message, baseStyle)
+	ml.drawHeader(builder, message, baseStyle)
 	contentRoot, contentSource := ml.drawContent(builder, message, baseStyle)
 
 	if message.EditedTimestamp.IsValid() {
 		dimStyle := baseStyle.Dim(true)
 		builder.Write(" (edited)", dimStyle)
 	}
+	if ml.chat.audit.isDeleted(message.ID) {
+		builder.Write(" (deleted)", baseStyle.Dim(true))
+	}
 
-	ml.drawEmbeds(builder, message, baseStyle, contentRoot, contentSource)
+	// The embeds of masked spoilers would give them away.
+	embedsMessage := message
+	embedsMessage.Embeds = ml.visibleEmbeds(message, contentRoot, contentSource)
+	ml.drawEmbeds(builder, embedsMessage, baseStyle, contentRoot, contentSource)
+	ml.drawEmbedImages(builder, embedsMessage)
 
 	attachmentStyle := tview.MergeStyle(baseStyle, ml.cfg.Theme.MessagesList.AttachmentStyle.Style)
 	for _, a := range message.Attachments {
 		builder.NewLine()
+		if isSpoilerAttachment(a) && ml.chat.spoilersMasked(message.ID) {
+			builder.Write("[spoiler attachment]", attachmentStyle)
+			continue
+		}
 		if ml.cfg.ShowAttachmentLinks {
 			builder.Write(a.Filename+":", attachmentStyle)
 			builder.NewLine()
@@ -495,7 +689,18 @@ func (ml *messagesList) drawDefaultMessage(builder *tview.LineBuilder, message d
 		} else {
 			builder.Write(a.Filename, attachmentStyle)
 		}
+
+		if img, ok := attachmentImage(a); ok {
+			ml.drawImagePreview(builder, img)
+		}
+	}
+
+	if message.Poll != nil {
+		ml.drawPoll(builder, *message.Poll, baseStyle)
 	}
+
+	ml.drawComponents(builder, ml.messageComponents(message), baseStyle)
+	ml.drawEphemeralNotice(builder, message, baseStyle)
 }
 
 func (ml *messagesList) drawEmbeds(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style, contentRoot ast.Node, contentSource []byte) {
@@ -521,9 +726,8 @@ func (ml *messagesList) drawEmbeds(builder *tview.LineBuilder, message discord.M
 	defaultBarStyle := baseStyle.Dim(true)
 	prefixText := "  ▎ "
 	prefixWidth := uniseg.StringWidth(prefixText)
-	_, _, innerWidth, _ := ml.InnerRect()
 	// Wrap against the current list viewport. This keeps embed wrapping stable even when sidebars/panes are resized.
-	wrapWidth := max(innerWidth-prefixWidth, 1)
+	wrapWidth := max(ml.bodyWidth()-prefixWidth, 1)
 
 	for _, embed := range message.Embeds {
 		lines := embedLines(embed, contentURLs)
@@ -803,8 +1007,7 @@ func isMarkdownEscapable(c byte) bool {
 
 func (ml *messagesList) drawForwardedMessage(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
 	dimStyle := baseStyle.Dim(true)
-	ml.drawTimestamps(builder, message.Timestamp, baseStyle)
-	ml.drawAuthor(builder, message, baseStyle)
+	ml.drawHeader(builder, message, baseStyle)
 	builder.Write(ml.cfg.Theme.MessagesList.ForwardedIndicator+" ", dimStyle)
 	ml.drawSnapshotContent(builder, message, message.MessageSnapshots[0].Message, baseStyle)
 	builder.Write(" ("+ml.formatTimestamp(message.MessageSnapshots[0].Message.Timestamp)+") ", dimStyle)
@@ -828,11 +1031,6 @@ func (ml *messagesList) drawReplyMessage(builder *tview.LineBuilder, message dis
 	ml.drawDefaultMessage(builder, message, baseStyle)
 }
 
-func (ml *messagesList) drawPinnedMessage(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
-	builder.Write(message.Author.DisplayOrUsername(), baseStyle)
-	builder.Write(" pinned a message.", baseStyle)
-}
-
 func (ml *messagesList) selectedMessage() (*discord.Message, bool) {
 	if len(ml.messages) == 0 {
 		return nil, false
@@ -848,28 +1046,62 @@ func (ml *messagesList) selectedMessage() (*discord.Message, bool) {
 
 func (ml *messagesList) Update(msg tview.Msg) tview.Cmd {
 	ui.UpdateBoxFocus(ml.Box, &ml.cfg.Theme, msg)
+	// The visual range follows the cursor however it moves.
+	defer ml.extendVisual()
 	switch msg := msg.(type) {
 	case tview.FocusMsg:
 		return tview.Sequence(ml.Model.Update(msg), focused(ml))
 	case tview.KeyMsg:
+		if ml.selectedMessageDeleted() && ml.isMessageAction(msg) {
+			return nil
+		}
+
 		switch {
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.Cancel.Keybind):
+			if ml.hasMarks() {
+				ml.clearMarks()
+				return nil
+			}
 			ml.clearSelection()
 			return nil
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.VisualMode.Keybind):
+			ml.toggleVisual()
+			return nil
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ToggleMark.Keybind):
+			ml.toggleMark()
+			return nil
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.Export.Keybind):
+			return ml.exportMessages()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ToggleSpoilers.Keybind):
+			ml.toggleSpoilers()
+			return nil
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.SelectUp.Keybind):
 			return ml.selectUp()
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.SelectDown.Keybind):
-			ml.selectDown()
-			return nil
+			return ml.selectDown()
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.SelectTop.Keybind):
 			ml.selectTop()
 			return nil
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.SelectBottom.Keybind):
+			// At the bottom of a detached window, page newer instead.
+			if ml.detached && len(ml.messages) > 0 && ml.Cursor() == len(ml.messages)-1 {
+				return ml.fetchNewerMessages()
+			}
 			ml.selectBottom()
 			return nil
+		case ml.detached && keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ScrollDown.Keybind):
+			return ml.scrollNewer(msg, false)
+		case ml.detached && keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ScrollBottom.Keybind):
+			return ml.scrollNewer(msg, true)
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.SelectReply.Keybind):
 			ml.selectReply()
 			return nil
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.JumpToUnread.Keybind):
+			return ml.jumpToUnread()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.MarkUnread.Keybind):
+			return ml.markUnread()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.JumpToPresent.Keybind):
+			return ml.jumpToPresent()
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.YankID.Keybind):
 			return ml.yankMessageID()
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.YankContent.Keybind):
@@ -888,9 +1120,50 @@ func (ml *messagesList) Update(msg tview.Msg) tview.Cmd {
 			return ml.deleteSelectedMessage()
 		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.DeleteConfirm.Keybind):
 			return ml.confirmDelete()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.AddReaction.Keybind):
+			return ml.showEmojiPicker()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ToggleReaction.Keybind):
+			return ml.showReactionsPicker()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.Vote.Keybind):
+			return ml.showPollPicker()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.RemoveVotes.Keybind):
+			return ml.removePollVotes()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.Interact.Keybind):
+			return ml.showComponentPicker()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ShowPins.Keybind):
+			return ml.showPins()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.TogglePin.Keybind):
+			return ml.togglePin()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ToggleThread.Keybind):
+			return ml.toggleThread()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.StartThread.Keybind):
+			return ml.startThread()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ToggleThreadMembership.Keybind):
+			return ml.toggleThreadMembership()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ToggleThreadArchived.Keybind):
+			return ml.toggleThreadArchived()
+		case keybind.Matches(msg, ml.cfg.Keybinds.MessagesList.ShowRevisions.Keybind):
+			return ml.showRevisions()
+		}
+	case newerMessagesLoadedMsg:
+		return ml.onNewerMessagesLoaded(msg)
+	case presentLoadedMsg:
+		selectedChannel, ok := ml.selectedChannel()
+		if !ok || selectedChannel.ID != msg.ChannelID {
+			return nil
+		}
+
+		ml.detached = false
+		ml.pending = nil
+		ml.highlightedID = 0
+		ml.setMessages(msg.Messages)
+		ml.selectBottom()
+		if selectedChannel.GuildID.IsValid() {
+			return ml.requestGuildMembers(selectedChannel.GuildID, msg.Messages)
 		}
+		return nil
 	case olderMessagesLoadedMsg:
-		selectedChannel, ok := ml.chat.SelectedChannel()
+		selectedChannel, ok := ml.selectedChannel()
 		if !ok || selectedChannel.ID != msg.ChannelID {
 			return nil
 		}
@@ -899,11 +1172,17 @@ func (ml *messagesList) Update(msg tview.Msg) tview.Cmd {
 		// Defensive invalidation if Discord returns overlapping windows.
 		for _, message := range msg.Older {
 			delete(ml.itemByID, message.ID)
+			delete(ml.componentsByID, message.ID)
 		}
 		ml.messages = slices.Concat(msg.Older, ml.messages)
 		ml.rebuildRows()
 
 		switch {
+		case msg.SelectUnread:
+			// The channel history may end before the read marker; the oldest
+			// message is then the first unread one.
+			index := max(ml.firstUnreadIndex(), 0)
+			ml.SetCursor(index)
 		case prevCursor == 0:
 			// Preserve "SelectUp at top" semantics: move to the next older message.
 			ml.SetCursor(len(msg.Older) - 1)
@@ -943,10 +1222,10 @@ func (ml *messagesList) selectUp() tview.Cmd {
 	return nil
 }
 
-func (ml *messagesList) selectDown() {
+func (ml *messagesList) selectDown() tview.Cmd {
 	messages := ml.messages
 	if len(messages) == 0 {
-		return
+		return nil
 	}
 
 	cursor := ml.Cursor()
@@ -955,9 +1234,12 @@ func (ml *messagesList) selectDown() {
 		cursor = len(messages) - 1
 	case cursor < len(messages)-1:
 		cursor++
+	case ml.detached:
+		return ml.fetchNewerMessages()
 	}
 
 	ml.SetCursor(cursor)
+	return nil
 }
 
 func (ml *messagesList) selectTop() {
@@ -974,6 +1256,23 @@ func (ml *messagesList) selectBottom() {
 	ml.SetCursor(len(ml.messages) - 1)
 }
 
+// highlightMessage selects the message with the given ID and draws it with
+// the highlighted style. It reports whether the message is loaded.
+func (ml *messagesList) highlightMessage(messageID discord.MessageID) bool {
+	index := slices.IndexFunc(ml.messages, func(m discord.Message) bool {
+		return m.ID == messageID
+	})
+	if index == -1 {
+		return false
+	}
+
+	delete(ml.itemByID, ml.highlightedID)
+	delete(ml.itemByID, messageID)
+	ml.highlightedID = messageID
+	ml.SetCursor(index)
+	return true
+}
+
 func (ml *messagesList) selectReply() {
 	messages := ml.messages
 	if len(messages) == 0 {
@@ -995,8 +1294,123 @@ func (ml *messagesList) selectReply() {
 	}
 }
 
+// scrollNewer scrolls a detached window and pages newer messages once the
+// scroll reaches its bottom: always for scroll bottom, and for scroll down when
+// the last message or no message is selected.
+func (ml *messagesList) scrollNewer(msg tview.KeyMsg, bottom bool) tview.Cmd {
+	cmd := ml.Model.Update(msg)
+	ml.onRowCursorChanged(ml.Model.Cursor())
+	if len(ml.messages) == 0 {
+		return cmd
+	}
+
+	cursor := ml.Cursor()
+	if bottom || cursor == -1 || cursor == len(ml.messages)-1 {
+		return tview.Batch(cmd, ml.fetchNewerMessages())
+	}
+	return cmd
+}
+
+// fetchNewerMessages extends a detached window towards the present.
+func (ml *messagesList) fetchNewerMessages() tview.Cmd {
+	selectedChannel, ok := ml.selectedChannel()
+	if !ok {
+		return nil
+	}
+
+	channelID := selectedChannel.ID
+	after := ml.messages[len(ml.messages)-1].ID
+	limit := uint(ml.cfg.MessagesLimit)
+	return func() tview.Msg {
+		messages, err := ml.chat.state.MessagesAfter(channelID, after, limit)
+		if err != nil {
+			slog.Error("failed to fetch newer messages", "err", err, "channel_id", channelID, "after", after)
+			return nil
+		}
+
+		newer := slices.Clone(messages)
+		slices.SortFunc(newer, func(a, b discord.Message) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+
+		// A short page means there is nothing newer left to fetch.
+		lastMessageID := ml.chat.state.LastMessage(channelID)
+		live := uint(len(newer)) < limit || (len(newer) > 0 && newer[len(newer)-1].ID >= lastMessageID)
+		if live && lastMessageID.IsValid() {
+			go ml.chat.state.ReadState.MarkRead(channelID, lastMessageID)
+		}
+		return newerMessagesLoadedMsg{ChannelID: channelID, Newer: newer, Live: live}
+	}
+}
+
+func (ml *messagesList) onNewerMessagesLoaded(msg newerMessagesLoadedMsg) tview.Cmd {
+	selectedChannel, ok := ml.selectedChannel()
+	if !ok || selectedChannel.ID != msg.ChannelID || !ml.detached || len(ml.messages) == 0 {
+		return nil
+	}
+
+	newer := msg.Newer
+	if msg.Live {
+		// Messages created while fetching may be in both the page and the
+		// buffer.
+		newer = append(newer, ml.pending...)
+		ml.pending = nil
+		ml.detached = false
+	}
+
+	prevCursor := ml.Cursor()
+	last := ml.messages[len(ml.messages)-1].ID
+	for _, message := range newer {
+		if message.ID <= last {
+			continue
+		}
+		last = message.ID
+		delete(ml.itemByID, message.ID)
+		delete(ml.componentsByID, message.ID)
+		ml.messages = append(ml.messages, message)
+	}
+	ml.rebuildRows()
+
+	// Preserve "SelectDown at bottom" semantics: move to the next newer message.
+	if prevCursor >= 0 {
+		ml.SetCursor(min(prevCursor+1, len(ml.messages)-1))
+	}
+	if selectedChannel.GuildID.IsValid() {
+		return ml.requestGuildMembers(selectedChannel.GuildID, msg.Newer)
+	}
+	return nil
+}
+
+// jumpToPresent discards a detached window and loads the latest messages.
+func (ml *messagesList) jumpToPresent() tview.Cmd {
+	if !ml.detached {
+		ml.selectBottom()
+		return nil
+	}
+
+	selectedChannel, ok := ml.selectedChannel()
+	if !ok {
+		return nil
+	}
+
+	channelID := selectedChannel.ID
+	limit := uint(ml.cfg.MessagesLimit)
+	return func() tview.Msg {
+		messages, err := ml.chat.state.Messages(channelID, limit)
+		if err != nil {
+			slog.Error("failed to get messages", "err", err, "channel_id", channelID, "limit", limit)
+			return nil
+		}
+
+		if lastMessageID := ml.chat.state.LastMessage(channelID); lastMessageID.IsValid() {
+			go ml.chat.state.ReadState.MarkRead(channelID, lastMessageID)
+		}
+		return presentLoadedMsg{ChannelID: channelID, Messages: messages}
+	}
+}
+
 func (ml *messagesList) fetchOlderMessages() tview.Cmd {
-	selectedChannel, ok := ml.chat.SelectedChannel()
+	selectedChannel, ok := ml.selectedChannel()
 	if !ok {
 		return nil
 	}
@@ -1020,6 +1434,58 @@ func (ml *messagesList) fetchOlderMessages() tview.Cmd {
 	}
 }
 
+func (ml *messagesList) jumpToUnread() tview.Cmd {
+	if !ml.lastReadID.IsValid() || len(ml.messages) == 0 {
+		return nil
+	}
+
+	if index := ml.firstUnreadIndex(); index >= 0 {
+		ml.SetCursor(index)
+		return nil
+	}
+
+	// Everything loaded has been read.
+	if ml.messages[0].ID <= ml.lastReadID {
+		return nil
+	}
+
+	selectedChannel, ok := ml.selectedChannel()
+	if !ok {
+		return nil
+	}
+
+	channelID := selectedChannel.ID
+	before := ml.messages[0].ID
+	lastReadID := ml.lastReadID
+	limit := uint(ml.cfg.MessagesLimit)
+	return func() tview.Msg {
+		var older []discord.Message
+		for range maxUnreadPages {
+			messages, err := ml.chat.state.MessagesBefore(channelID, before, limit)
+			if err != nil {
+				slog.Error("failed to fetch older messages", "err", err)
+				break
+			}
+			if len(messages) == 0 {
+				break
+			}
+
+			// Pages are returned newest first.
+			older = append(older, messages...)
+			before = messages[len(messages)-1].ID
+			if before <= lastReadID {
+				break
+			}
+		}
+		if len(older) == 0 {
+			return nil
+		}
+
+		slices.Reverse(older)
+		return olderMessagesLoadedMsg{ChannelID: channelID, Older: older, SelectUnread: true}
+	}
+}
+
 func (ml *messagesList) yankMessageID() tview.Cmd {
 	selectedMessage, ok := ml.selectedMessage()
 	if !ok {
@@ -1036,6 +1502,10 @@ func (ml *messagesList) yankMessageID() tview.Cmd {
 }
 
 func (ml *messagesList) yankContent() tview.Cmd {
+	if ml.hasMarks() {
+		return ml.yankTranscript()
+	}
+
 	selectedMessage, ok := ml.selectedMessage()
 	if !ok {
 		return nil
@@ -1078,7 +1548,7 @@ func (ml *messagesList) open() tview.Cmd {
 	case total > 1:
 		return ml.showAttachmentsList(urls, selectedMessage.Attachments)
 	case len(urls) == 1:
-		return openURL(urls[0])
+		return openLink(urls[0])
 	}
 
 	attachment := selectedMessage.Attachments[0]
@@ -1089,12 +1559,18 @@ func (ml *messagesList) open() tview.Cmd {
 }
 
 func extractURLs(content string) []string {
+	return urlsFromAST(parseLinks(content))
+}
+
+// parseLinks parses the content with the Discord syntax, such as links and
+// spoilers.
+func parseLinks(content string) (ast.Node, []byte) {
 	src := []byte(content)
 	node := parser.NewParser(
 		parser.WithBlockParsers(discordmd.BlockParsers()...),
 		parser.WithInlineParsers(discordmd.InlineParserWithLink()...),
 	).Parse(text.NewReader(src))
-	return urlsFromAST(node, src)
+	return node, src
 }
 
 // urlsFromAST collects link destinations from an already-parsed markdown AST.
@@ -1169,7 +1645,7 @@ func (ml *messagesList) showAttachmentsList(urls []string, attachments []discord
 		url := u
 		items = append(items, attachmentspicker.Item{
 			Label: url,
-			Open:  openURL(url),
+			Open:  openLink(url),
 		})
 	}
 	ml.attachmentsPicker.SetItems(items)
@@ -1248,7 +1724,7 @@ func (ml *messagesList) reply(mention bool) tview.Cmd {
 		name = member.Nick
 	}
 
-	data := ml.chat.composer.sendMessageData
+	data := ml.composer.sendMessageData
 	data.Reference = &discord.MessageReference{MessageID: selectedMessage.ID}
 	data.AllowedMentions = &api.AllowedMentions{RepliedUser: option.Some(false)}
 
@@ -1258,9 +1734,9 @@ func (ml *messagesList) reply(mention bool) tview.Cmd {
 		title = "[@] " + title
 	}
 
-	ml.chat.composer.sendMessageData = data
-	ml.chat.composer.SetTitle(title + name)
-	return tview.SetFocus(ml.chat.composer)
+	ml.composer.sendMessageData = data
+	ml.composer.SetTitle(title + name)
+	return tview.SetFocus(ml.composer)
 }
 
 func (ml *messagesList) editSelectedMessage() tview.Cmd {
@@ -1274,13 +1750,17 @@ func (ml *messagesList) editSelectedMessage() tview.Cmd {
 		return nil
 	}
 
-	ml.chat.composer.SetTitle("Editing")
-	ml.chat.composer.edit = true
-	ml.chat.composer.SetText(selectedMessage.Content, true)
-	return tview.SetFocus(ml.chat.composer)
+	ml.composer.SetTitle("Editing")
+	ml.composer.edit = true
+	ml.composer.SetText(selectedMessage.Content, true)
+	return tview.SetFocus(ml.composer)
 }
 
 func (ml *messagesList) confirmDelete() tview.Cmd {
+	if ml.hasMarks() {
+		return ml.confirmBulkDelete()
+	}
+
 	selectedMessage, ok := ml.selectedMessage()
 	if !ok || !ml.canDeleteMessage(*selectedMessage) {
 		return nil
@@ -1294,6 +1774,11 @@ func (ml *messagesList) confirmDelete() tview.Cmd {
 }
 
 func (ml *messagesList) deleteSelectedMessage() tview.Cmd {
+	// Deleting many messages always asks first.
+	if ml.hasMarks() {
+		return ml.confirmBulkDelete()
+	}
+
 	selectedMessage, ok := ml.selectedMessage()
 	if !ok {
 		return nil
@@ -1322,10 +1807,138 @@ func (ml *messagesList) deleteMessageRequest(message discord.Message) tview.Cmd
 }
 
 func (ml *messagesList) canDeleteMessage(message discord.Message) bool {
+	// Ephemeral messages only exist on the client.
+	if message.Flags&discord.EphemeralMessage != 0 {
+		return false
+	}
 	return ml.chat.isMe(message.Author.ID) ||
 		(message.GuildID.IsValid() && ml.chat.state.HasPermissions(message.ChannelID, discord.PermissionManageMessages))
 }
 
+// canPinMessages reports whether the user may pin and unpin messages in the
+// channel. Anyone may pin in direct messages.
+func (ml *messagesList) canPinMessages(message discord.Message) bool {
+	return !message.GuildID.IsValid() || ml.chat.state.HasPermissions(message.ChannelID, discord.PermissionManageMessages)
+}
+
+// showPins lists the pins of the channel of the list, which is the thread in
+// the thread pane.
+func (ml *messagesList) showPins() tview.Cmd {
+	selectedChannel, ok := ml.selectedChannel()
+	if !ok {
+		return nil
+	}
+	return ml.chat.showPins(*selectedChannel)
+}
+
+func (ml *messagesList) togglePin() tview.Cmd {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	message := *selectedMessage
+	if !ml.canPinMessages(message) {
+		slog.Error("failed to toggle pin; missing relevant permissions", "channel_id", message.ChannelID, "message_id", message.ID)
+		return nil
+	}
+
+	return func() tview.Msg {
+		if message.Pinned {
+			if err := ml.chat.state.UnpinMessage(message.ChannelID, message.ID, ""); err != nil {
+				slog.Error("failed to unpin message", "err", err, "channel_id", message.ChannelID, "message_id", message.ID)
+			}
+			return nil
+		}
+
+		if err := ml.chat.state.PinMessage(message.ChannelID, message.ID, ""); err != nil {
+			slog.Error("failed to pin message", "err", err, "channel_id", message.ChannelID, "message_id", message.ID)
+		}
+		return nil
+	}
+}
+
+func (ml *messagesList) canAddReactions(message discord.Message) bool {
+	return !message.GuildID.IsValid() || ml.chat.state.HasPermissions(message.ChannelID, discord.PermissionAddReactions)
+}
+
+func (ml *messagesList) showEmojiPicker() tview.Cmd {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	if !ml.canAddReactions(*selectedMessage) {
+		slog.Error("failed to add reaction; missing relevant permissions", "channel_id", selectedMessage.ChannelID, "message_id", selectedMessage.ID)
+		return nil
+	}
+
+	var custom []discord.Emoji
+	if guildID := selectedMessage.GuildID; guildID.IsValid() {
+		emojis, err := ml.chat.state.Cabinet.Emojis(guildID)
+		if err != nil {
+			slog.Error("failed to get emojis from state", "err", err, "guild_id", guildID)
+		}
+		custom = emojis
+	}
+
+	ml.emojiPicker.SetEmojis(custom)
+	return ml.openEmojiPicker(selectedMessage.ID)
+}
+
+func (ml *messagesList) showReactionsPicker() tview.Cmd {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok || len(selectedMessage.Reactions) == 0 {
+		return nil
+	}
+
+	ml.emojiPicker.SetReactions(selectedMessage.Reactions)
+	return ml.openEmojiPicker(selectedMessage.ID)
+}
+
+func (ml *messagesList) openEmojiPicker(messageID discord.MessageID) tview.Cmd {
+	ml.reactionMessageID = messageID
+	ml.chat.
+		AddLayer(
+			ui.Centered(ml.emojiPicker, ml.cfg.Picker.Width, ml.cfg.Picker.Height),
+			layers.WithName(emojiPickerLayerName),
+			layers.WithResize(true),
+			layers.WithVisible(true),
+			layers.WithOverlay(),
+		).
+		SendToFront(emojiPickerLayerName)
+	return tview.SetFocus(ml.emojiPicker)
+}
+
+// toggleReaction removes the user's reaction if it is already on the message
+// the picker was opened for, and adds it otherwise.
+func (ml *messagesList) toggleReaction(emoji discord.APIEmoji) tview.Cmd {
+	index := slices.IndexFunc(ml.messages, func(m discord.Message) bool {
+		return m.ID == ml.reactionMessageID
+	})
+	if index < 0 {
+		return nil
+	}
+
+	message := ml.messages[index]
+	reacted := slices.ContainsFunc(message.Reactions, func(reaction discord.Reaction) bool {
+		return reaction.Me && reaction.Emoji.APIString() == emoji
+	})
+	return func() tview.Msg {
+		if reacted {
+			if err := ml.chat.state.Unreact(message.ChannelID, message.ID, emoji); err != nil {
+				slog.Error("failed to remove reaction", "channel_id", message.ChannelID, "message_id", message.ID, "emoji", emoji, "err", err)
+			}
+			return nil
+		}
+
+		if err := ml.chat.state.React(message.ChannelID, message.ID, emoji); err != nil {
+			slog.Error("failed to add reaction", "channel_id", message.ChannelID, "message_id", message.ID, "emoji", emoji, "err", err)
+		}
+		return nil
+	}
+}
+
 func (ml *messagesList) requestGuildMembers(guildID discord.GuildID, messages []discord.Message) tview.Cmd {
 	usersToFetch := make([]discord.UserID, 0, len(messages))
 	seen := make(map[discord.UserID]struct{}, len(messages))
@@ -1390,22 +2003,73 @@ func (ml *messagesList) FullHelp() [][]keybind.Keybind {
 	canEdit := false
 	canDelete := false
 	canOpen := false
-	if selectedMessage, ok := ml.selectedMessage(); ok {
+	canReact := false
+	hasReactions := false
+	canPin := false
+	hasThread := false
+	canVote := false
+	hasRevisions := false
+	canInteract := false
+	hasSpoilers := false
+	canMarkUnread := false
+	if selectedMessage, ok := ml.selectedMessage(); ok && ml.chat.audit.isDeleted(selectedMessage.ID) {
+		canSelectReply = selectedMessage.ReferencedMessage != nil
+		canOpen = len(messageURLs(*selectedMessage)) != 0 || len(selectedMessage.Attachments) != 0
+		hasThread = ml.threadOf(*selectedMessage) != nil
+		hasRevisions = len(ml.chat.audit.history(*selectedMessage)) != 0
+		hasSpoilers = ml.cfg.Markdown.MaskSpoilers && messageHasSpoilers(*selectedMessage)
+	} else if ok {
+		canMarkUnread = true
 		canSelectReply = selectedMessage.ReferencedMessage != nil
 		canOpen = len(messageURLs(*selectedMessage)) != 0 || len(selectedMessage.Attachments) != 0
 
 		canEdit = ml.chat.isMe(selectedMessage.Author.ID)
 		canReply = !canEdit
 		canDelete = ml.canDeleteMessage(*selectedMessage)
+		canReact = ml.canAddReactions(*selectedMessage)
+		hasReactions = len(selectedMessage.Reactions) != 0
+		canPin = ml.canPinMessages(*selectedMessage)
+		hasThread = ml.threadOf(*selectedMessage) != nil
+		canVote = selectedMessage.Poll != nil && !pollClosed(*selectedMessage.Poll)
+		canInteract = ml.hasInteractiveComponents(*selectedMessage)
+		hasRevisions = len(ml.chat.audit.history(*selectedMessage)) != 0
+		hasSpoilers = ml.cfg.Markdown.MaskSpoilers && messageHasSpoilers(*selectedMessage)
 	}
 
-	actions := make([]keybind.Keybind, 0, 4)
+	actions := make([]keybind.Keybind, 0, 8)
+	if ml.lastReadID.IsValid() {
+		actions = append(actions, cfg.JumpToUnread.Keybind)
+	}
+	if canMarkUnread {
+		actions = append(actions, cfg.MarkUnread.Keybind)
+	}
+	if ml.detached {
+		actions = append(actions, cfg.JumpToPresent.Keybind)
+	}
+	if hasThread || ml.thread != nil {
+		actions = append(actions, cfg.ToggleThread.Keybind)
+	}
 	if canReply {
 		actions = append(actions, cfg.Reply.Keybind, cfg.ReplyMention.Keybind)
 	}
 	if canSelectReply {
 		actions = append(actions, cfg.SelectReply.Keybind)
 	}
+	if canReact {
+		actions = append(actions, cfg.AddReaction.Keybind)
+	}
+	if hasReactions {
+		actions = append(actions, cfg.ToggleReaction.Keybind)
+	}
+	if canVote {
+		actions = append(actions, cfg.Vote.Keybind, cfg.RemoveVotes.Keybind)
+	}
+	if canInteract {
+		actions = append(actions, cfg.Interact.Keybind)
+	}
+	if hasSpoilers {
+		actions = append(actions, cfg.ToggleSpoilers.Keybind)
+	}
 	actions = append(actions, cfg.Cancel.Keybind)
 
 	manage := make([]keybind.Keybind, 0, 4)
@@ -1418,12 +2082,32 @@ func (ml *messagesList) FullHelp() [][]keybind.Keybind {
 	if canOpen {
 		manage = append(manage, cfg.Open.Keybind)
 	}
+	if canPin {
+		manage = append(manage, cfg.TogglePin.Keybind)
+	}
+	if hasRevisions {
+		manage = append(manage, cfg.ShowRevisions.Keybind)
+	}
+	manage = append(manage, cfg.ShowPins.Keybind)
+
+	threads := make([]keybind.Keybind, 0, 3)
+	if ml.canStartThread() {
+		threads = append(threads, cfg.StartThread.Keybind)
+	}
+	if thread, ok := ml.currentThread(); ok {
+		threads = append(threads, cfg.ToggleThreadMembership.Keybind)
+		if ml.canArchiveThread(*thread) {
+			threads = append(threads, cfg.ToggleThreadArchived.Keybind)
+		}
+	}
 
 	return [][]keybind.Keybind{
 		{cfg.SelectUp.Keybind, cfg.SelectDown.Keybind, cfg.SelectTop.Keybind, cfg.SelectBottom.Keybind},
 		{cfg.ScrollUp.Keybind, cfg.ScrollDown.Keybind, cfg.ScrollTop.Keybind, cfg.ScrollBottom.Keybind},
 		actions,
 		manage,
+		threads,
+		{cfg.VisualMode.Keybind, cfg.ToggleMark.Keybind, cfg.Export.Keybind},
 		{cfg.YankContent.Keybind, cfg.YankURL.Keybind, cfg.YankID.Keybind},
 	}
 }
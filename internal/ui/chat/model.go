@@ -3,6 +3,7 @@ package chat
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 
@@ -20,12 +21,17 @@ import (
 	"github.com/ayn2op/discordo/internal/ui"
 	"github.com/ayn2op/discordo/internal/ui/chat/attachmentspicker"
 	"github.com/ayn2op/discordo/internal/ui/chat/channelspicker"
+	"github.com/ayn2op/discordo/internal/ui/chat/componentpicker"
+	"github.com/ayn2op/discordo/internal/ui/chat/emojipicker"
+	"github.com/ayn2op/discordo/internal/ui/chat/pollpicker"
+	"github.com/ayn2op/discordo/internal/ui/chat/searchpicker"
 	"github.com/ayn2op/ningen/v3"
 	"github.com/ayn2op/ningen/v3/states/read"
 	"github.com/ayn2op/tview"
 	"github.com/ayn2op/tview/flex"
 	"github.com/ayn2op/tview/keybind"
 	"github.com/ayn2op/tview/layers"
+	"github.com/ayn2op/tview/tree"
 	"github.com/gdamore/tcell/v3"
 )
 
@@ -36,13 +42,23 @@ const (
 	mentionsListLayerName = "mentionsList"
 
 	channelsPickerLayerName    = "channelsPicker"
+	searchPromptLayerName      = "searchPrompt"
+	searchResultsLayerName     = "searchResults"
+	promptLayerName            = "prompt"
+	pinsListLayerName          = "pinsList"
 	attachmentsPickerLayerName = "attachmentsPicker"
+	emojiPickerLayerName       = "emojiPicker"
+	pollPickerLayerName        = "pollPicker"
+	revisionsLayerName         = "revisions"
+	componentPickerLayerName   = "componentPicker"
+	interactionModalLayerName  = "interactionModal"
 )
 
 type Model struct {
 	*layers.Layers
 
-	// guildsTree (sidebar) + rightFlex
+	// guildsTree (sidebar) + rightFlex, then the thread pane and the member
+	// list when shown
 	mainFlex *flex.Model
 	// messagesList + composer
 	rightFlex *flex.Model
@@ -51,13 +67,31 @@ type Model struct {
 	messagesList   *messagesList
 	composer       *composer
 	channelsPicker *channelspicker.Model
+	searchPrompt   *searchpicker.Prompt
+	searchPicker   *searchpicker.Model
+	prompt         *prompt
+	pinsList       *pinsList
+	revisionsView  *revisionsView
+	threadPane     *threadPane
+	memberList     *memberList
 	focused        tview.Model
 
+	// imagePreviews is nil when image previews are disabled.
+	imagePreviews *imagePreviews
+	// audit is nil unless audit mode is enabled.
+	audit *auditLog
+	// revealedSpoilers holds the messages whose spoilers were revealed; they
+	// stay revealed when the messages are rendered again.
+	revealedSpoilers map[discord.MessageID]struct{}
+
 	selectedChannel   *discord.Channel
 	selectedChannelMu sync.RWMutex
 
 	state  *ningen.State
 	events chan gateway.Event
+	// sessionID identifies the gateway session to which the responses to
+	// interactions are sent.
+	sessionID string
 
 	typersMu sync.RWMutex
 	typers   map[discord.UserID]*time.Timer
@@ -72,7 +106,8 @@ func NewModel(cfg *config.Config, token string) *Model {
 		mainFlex:  flex.NewModel(),
 		rightFlex: flex.NewModel(),
 
-		typers: make(map[discord.UserID]*time.Timer),
+		typers:           make(map[discord.UserID]*time.Timer),
+		revealedSpoilers: make(map[discord.MessageID]struct{}),
 
 		cfg: cfg,
 	}
@@ -103,10 +138,20 @@ func NewModel(cfg *config.Config, token string) *Model {
 	}
 	m.state.OnRequest = append(m.state.OnRequest, httputil.WithHeaders(http.Headers()), m.onRequest)
 
+	m.imagePreviews = newImagePreviews(cfg)
+	m.audit = newAuditLog(cfg)
 	m.guildsTree = newGuildsTree(cfg, m.state)
 	m.messagesList = newMessagesList(cfg, m)
 	m.composer = newComposer(cfg, m)
+	bindComposer(m.messagesList, m.composer, m.rightFlex)
+	m.threadPane = newThreadPane(cfg, m)
+	m.memberList = newMemberList(cfg, m)
 	m.channelsPicker = channelspicker.NewModel(cfg)
+	m.searchPrompt = searchpicker.NewPrompt(cfg)
+	m.searchPicker = searchpicker.NewModel(cfg)
+	m.prompt = newPrompt(cfg)
+	m.pinsList = newPinsList(cfg, m)
+	m.revisionsView = newRevisionsView(cfg, m)
 
 	m.SetBackgroundLayerStyle(m.cfg.Theme.Dialog.BackgroundStyle.Style)
 	m.buildLayout()
@@ -143,6 +188,12 @@ func (m *Model) buildLayout() {
 	m.mainFlex.
 		AddItem(m.guildsTree, 0, m.cfg.Sidebar.WidthPercent, true).
 		AddItem(m.rightFlex, 0, 100-m.cfg.Sidebar.WidthPercent, false)
+	if m.threadPane.visible {
+		m.mainFlex.AddItem(m.threadPane, 0, threadPaneProportion(m.cfg), false)
+	}
+	if m.memberList.visible {
+		m.mainFlex.AddItem(m.memberList, 0, m.cfg.MemberList.WidthPercent, false)
+	}
 
 	m.AddLayer(m.mainFlex, layers.WithName(flexLayerName), layers.WithResize(true), layers.WithVisible(true))
 	m.AddLayer(
@@ -181,24 +232,45 @@ func (m *Model) closePicker() tview.Cmd {
 
 func (m *Model) closeAttachmentsPicker() tview.Cmd {
 	m.RemoveLayer(attachmentsPickerLayerName)
-	return tview.SetFocus(m.messagesList)
+	return tview.SetFocus(m.activeMessagesList())
 }
 
-func (m *Model) navigateToChannel(channelID discord.ChannelID) tview.Cmd {
+func (m *Model) closeEmojiPicker() tview.Cmd {
+	m.RemoveLayer(emojiPickerLayerName)
+	return tview.SetFocus(m.activeMessagesList())
+}
+
+func (m *Model) closePollPicker() tview.Cmd {
+	m.RemoveLayer(pollPickerLayerName)
+	return tview.SetFocus(m.activeMessagesList())
+}
+
+// revealChannel expands the guilds tree down to the channel and moves the
+// tree cursor onto it.
+func (m *Model) revealChannel(channelID discord.ChannelID) (*discord.Channel, *tree.Node) {
 	channel, err := m.state.Cabinet.Channel(channelID)
 	if err != nil {
 		slog.Error("failed to get channel from state", "err", err, "channel_id", channelID)
-		return nil
+		return nil, nil
 	}
 
 	node := m.guildsTree.findNodeByChannelID(channel.ID)
 	if node == nil {
 		slog.Error("failed to locate channel in tree", "channel_id", channel.ID)
-		return nil
+		return nil, nil
 	}
 
 	m.guildsTree.expandPathToNode(node)
 	m.guildsTree.SetCurrentNode(node)
+	return channel, node
+}
+
+func (m *Model) navigateToChannel(channelID discord.ChannelID) tview.Cmd {
+	channel, node := m.revealChannel(channelID)
+	if channel == nil {
+		return nil
+	}
+
 	focus := m.closePicker()
 	if channel.Type != discord.GuildCategory {
 		return tview.Sequence(focus, m.guildsTree.onSelected(node))
@@ -206,8 +278,35 @@ func (m *Model) navigateToChannel(channelID discord.ChannelID) tview.Cmd {
 	return focus
 }
 
+// navigateToMessage opens the channel with a window of messages around the
+// given message instead of the latest ones.
+func (m *Model) navigateToMessage(channelID discord.ChannelID, messageID discord.MessageID) tview.Cmd {
+	channel, _ := m.revealChannel(channelID)
+	if channel == nil {
+		return nil
+	}
+
+	// Avoid refetching when the message is already loaded.
+	if selected, ok := m.SelectedChannel(); ok && selected.ID == channelID && m.messagesList.highlightMessage(messageID) {
+		return tview.SetFocus(m.messagesList)
+	}
+	return tview.Sequence(tview.SetFocus(m.messagesList), m.guildsTree.loadChannelAround(*channel, messageID))
+}
+
+// guildsTreeVisible reports whether the guilds tree is part of the layout.
+func (m *Model) guildsTreeVisible() bool {
+	count := m.mainFlex.GetItemCount()
+	if m.threadPane.visible {
+		count--
+	}
+	if m.memberList.visible {
+		count--
+	}
+	return count == 2
+}
+
 func (m *Model) toggleGuildsTree() tview.Cmd {
-	if m.mainFlex.GetItemCount() == 2 {
+	if m.guildsTreeVisible() {
 		m.mainFlex.RemoveItem(m.guildsTree)
 		if m.guildsTree.HasFocus() {
 			return tview.SetFocus(m.mainFlex)
@@ -220,7 +319,7 @@ func (m *Model) toggleGuildsTree() tview.Cmd {
 }
 
 func (m *Model) focusGuildsTree() tview.Cmd {
-	if m.mainFlex.GetItemCount() == 2 {
+	if m.guildsTreeVisible() {
 		return tview.SetFocus(m.guildsTree)
 	}
 	return nil
@@ -233,45 +332,46 @@ func (m *Model) focusComposer() tview.Cmd {
 	return nil
 }
 
-func (m *Model) focusPrevious() tview.Cmd {
-	switch m.focused {
-	case m.guildsTree:
-		if cmd := m.focusComposer(); cmd != nil {
-			return cmd
-		}
-		return tview.SetFocus(m.messagesList)
-	case m.messagesList:
-		if cmd := m.focusGuildsTree(); cmd != nil {
-			return cmd
-		}
-		if cmd := m.focusComposer(); cmd != nil {
-			return cmd
+// focusCycle returns the models focusNext and focusPrevious cycle through,
+// from left to right.
+func (m *Model) focusCycle() []tview.Model {
+	var models []tview.Model
+	if m.guildsTreeVisible() {
+		models = append(models, m.guildsTree)
+	}
+	models = append(models, m.messagesList)
+	if !m.composer.Disabled() {
+		models = append(models, m.composer)
+	}
+	if tp := m.threadPane; tp.visible {
+		models = append(models, tp.messagesList)
+		if !tp.composer.Disabled() {
+			models = append(models, tp.composer)
 		}
-		return tview.SetFocus(m.messagesList)
-	case m.composer:
-		return tview.SetFocus(m.messagesList)
 	}
-	return nil
+	if m.memberList.visible {
+		models = append(models, m.memberList)
+	}
+	return models
+}
+
+func (m *Model) focusPrevious() tview.Cmd {
+	return m.focusOffset(-1)
 }
 
 func (m *Model) focusNext() tview.Cmd {
-	switch m.focused {
-	case m.guildsTree:
-		return tview.SetFocus(m.messagesList)
-	case m.messagesList:
-		if cmd := m.focusComposer(); cmd != nil {
-			return cmd
-		}
-		if cmd := m.focusGuildsTree(); cmd != nil {
-			return cmd
-		}
-	case m.composer:
-		if cmd := m.focusGuildsTree(); cmd != nil {
-			return cmd
-		}
-		return tview.SetFocus(m.messagesList)
+	return m.focusOffset(1)
+}
+
+// focusOffset focuses the model offset places from the focused one in the
+// focus cycle, wrapping around.
+func (m *Model) focusOffset(offset int) tview.Cmd {
+	models := m.focusCycle()
+	index := slices.Index(models, m.focused)
+	if index < 0 {
+		return nil
 	}
-	return nil
+	return tview.SetFocus(models[(index+offset+len(models))%len(models)])
 }
 
 func (m *Model) Update(msg tview.Msg) tview.Cmd {
@@ -280,7 +380,7 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 		m.focused = msg.Model
 		return nil
 	case tview.InitMsg:
-		return tview.Batch(openState(m.state), listen(m.events))
+		return tview.Batch(openState(m.state), listen(m.events), m.listenImages(), tickRelativeTimestamps())
 	case gateway.Event:
 		switch eventMsg := msg.(type) {
 		case *ws.RawEvent:
@@ -295,8 +395,14 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 			m.onMessageUpdate(eventMsg)
 		case *gateway.PresenceUpdateEvent:
 			m.onPresenceUpdate(eventMsg)
+		case *gateway.GuildMemberListUpdate:
+			m.memberList.onMemberListUpdate(eventMsg)
 		case *gateway.MessageDeleteEvent:
-			m.onMessageDelete(eventMsg)
+			m.onMessageDelete(eventMsg.ChannelID, eventMsg.ID)
+		case *gateway.MessageDeleteBulkEvent:
+			for _, messageID := range eventMsg.IDs {
+				m.onMessageDelete(eventMsg.ChannelID, messageID)
+			}
 		case *gateway.MessageReactionAddEvent:
 			m.onMessageReaction(eventMsg.ChannelID, eventMsg.MessageID)
 		case *gateway.MessageReactionAddManyEvent:
@@ -307,6 +413,22 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 			m.onMessageReaction(eventMsg.ChannelID, eventMsg.MessageID)
 		case *gateway.MessageReactionRemoveEmojiEvent:
 			m.onMessageReaction(eventMsg.ChannelID, eventMsg.MessageID)
+		case *gateway.MessagePollVoteAddEvent:
+			m.onPollVote(eventMsg.ChannelID, eventMsg.MessageID, eventMsg.UserID, eventMsg.AnswerID, true)
+		case *gateway.MessagePollVoteRemoveEvent:
+			m.onPollVote(eventMsg.ChannelID, eventMsg.MessageID, eventMsg.UserID, eventMsg.AnswerID, false)
+
+		case *gateway.ThreadCreateEvent:
+			m.guildsTree.onThreadUpdate(eventMsg.Channel)
+			m.onThreadUpdate(eventMsg.Channel)
+		case *gateway.ThreadUpdateEvent:
+			m.guildsTree.onThreadUpdate(eventMsg.Channel)
+			m.onThreadUpdate(eventMsg.Channel)
+		case *gateway.ThreadDeleteEvent:
+			m.guildsTree.removeChannelNode(eventMsg.ID)
+			if thread, ok := m.threadPane.thread(); ok && thread.ID == eventMsg.ID {
+				return tview.Batch(m.closeThread(), listen(m.events))
+			}
 
 		case *gateway.GuildMembersChunkEvent:
 			return tview.Batch(m.onGuildMembersChunk(eventMsg), listen(m.events))
@@ -318,10 +440,23 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 				m.onTypingStart(eventMsg)
 			}
 
+		case *gateway.UserGuildSettingsUpdateEvent:
+			m.guildsTree.onNotificationSettingsUpdate(eventMsg.GuildID)
 		case *read.UpdateEvent:
 			m.onReadUpdate(eventMsg)
+
+		case *interactionModalCreateEvent:
+			return tview.Batch(m.openInteractionModal(eventMsg), listen(m.events))
+		case *interactionFailureEvent:
+			return tview.Batch(ui.ShowModal("The application did not respond.", ui.ModalButton{Label: "Close"}), listen(m.events))
+		case *autocompleteResponseEvent:
+			return tview.Batch(m.composer.onAutocompleteResponse(eventMsg), m.threadPane.composer.onAutocompleteResponse(eventMsg), listen(m.events))
 		}
 		return listen(m.events)
+	case imageLoadedMsg:
+		return m.onImageLoaded(msg)
+	case relativeTimestampTickMsg:
+		return m.onRelativeTimestampTick()
 	case channelLoadedMsg:
 		node := m.guildsTree.CurrentNode()
 		if node == nil {
@@ -338,8 +473,14 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 
 		m.messagesList.reset()
 		m.messagesList.setTitle(msg.Channel)
+		m.messagesList.lastReadID = msg.LastReadID
+		m.messagesList.detached = msg.Detached
 		m.messagesList.setMessages(msg.Messages)
-		m.messagesList.ScrollBottom()
+		if msg.TargetID.IsValid() {
+			m.messagesList.highlightMessage(msg.TargetID)
+		} else {
+			m.messagesList.ScrollBottom()
+		}
 
 		isDM := msg.Channel.Type == discord.DirectMessage || msg.Channel.Type == discord.GroupDM
 		hasNoPerm := !isDM && !m.state.HasPermissions(msg.Channel.ID, discord.PermissionSendMessages)
@@ -350,24 +491,61 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 
 		if hasNoPerm {
 			text = "You do not have permission to send messages in this channel."
-		} else if m.cfg.AutoFocus {
+		} else if m.cfg.AutoFocus && !msg.TargetID.IsValid() {
 			focusCmd = m.focusComposer()
 		}
 		m.composer.SetPlaceholder(tview.NewLine(tview.NewSegment(text, tcell.StyleDefault.Dim(true))))
+		if m.memberList.visible {
+			focusCmd = tview.Batch(focusCmd, m.memberList.setChannel(msg.Channel))
+		}
 		if msg.Channel.GuildID.IsValid() {
 			return tview.Batch(focusCmd, m.messagesList.requestGuildMembers(msg.Channel.GuildID, msg.Messages))
 		}
 		return focusCmd
 	case deleteMessageMsg:
 		return m.messagesList.deleteMessageRequest(discord.Message(msg))
+	case deleteMessagesMsg:
+		return m.activeMessagesList().deleteMessagesRequest(msg)
 	case channelspicker.SelectedMsg:
 		return m.navigateToChannel(msg.ChannelID)
 	case channelspicker.CancelMsg:
 		return m.closePicker()
+	case searchpicker.QueryMsg:
+		return m.search(msg.Query)
+	case searchResultsMsg:
+		return m.openSearchResults(msg.Results, msg.Total)
+	case searchpicker.SelectedMsg:
+		return tview.Sequence(m.closeSearch(), m.navigateToMessage(msg.ChannelID, msg.MessageID))
+	case searchpicker.CancelMsg:
+		return m.closeSearch()
+	case messageLinkMsg:
+		return m.goToLink(msg.Link)
+	case closePromptMsg:
+		return m.closePrompt()
+	case pinsLoadedMsg:
+		return m.openPins(msg.Channel, msg.Messages)
+	case threadLoadedMsg:
+		return m.onThreadLoaded(msg)
 	case attachmentspicker.SelectedMsg:
 		return tview.Sequence(msg.Open, m.closeAttachmentsPicker())
 	case attachmentspicker.CancelMsg:
 		return m.closeAttachmentsPicker()
+	case emojipicker.SelectedMsg:
+		return tview.Sequence(m.closeEmojiPicker(), m.activeMessagesList().toggleReaction(msg.Emoji))
+	case emojipicker.CancelMsg:
+		return m.closeEmojiPicker()
+	case pollpicker.SelectedMsg:
+		return tview.Sequence(m.closePollPicker(), m.activeMessagesList().vote(msg.AnswerID))
+	case pollpicker.CancelMsg:
+		return m.closePollPicker()
+	case componentpicker.SelectedMsg:
+		return tview.Sequence(m.closeComponentPicker(), msg.Activate)
+	case componentpicker.CancelMsg:
+		return m.closeComponentPicker()
+	case selectMenuMsg:
+		return m.activeMessagesList().showSelectOptions(selectMenu(msg))
+	case toggleSelectOptionMsg:
+		return m.activeMessagesList().toggleSelectOption(string(msg))
 	case QuitMsg:
 		return closeState(m.state)
 	case tview.KeyMsg:
@@ -390,12 +568,36 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 			return m.toggleGuildsTree()
 		case keybind.Matches(msg, m.cfg.Keybinds.ToggleChannelsPicker.Keybind):
 			return m.togglePicker()
+		case keybind.Matches(msg, m.cfg.Keybinds.ToggleSearch.Keybind):
+			return m.toggleSearch()
+		case keybind.Matches(msg, m.cfg.Keybinds.ToggleMemberList.Keybind):
+			return m.toggleMemberList()
+		case keybind.Matches(msg, m.cfg.Keybinds.GoToLink.Keybind):
+			return m.toggleLinkPrompt()
+		case keybind.Matches(msg, m.cfg.Keybinds.NextUnread.Keybind):
+			return m.goToNextUnread(false)
+		case keybind.Matches(msg, m.cfg.Keybinds.NextMention.Keybind):
+			return m.goToNextUnread(true)
 
 		case keybind.Matches(msg, m.cfg.Keybinds.Logout.Keybind):
 			return tview.Sequence(closeState(m.state), logout())
 		}
 	case tabSuggestMsg:
 		return m.composer.Update(msg)
+	case mentionUserMsg:
+		return m.mentionUser(discord.User(msg))
+	case directMessageMsg:
+		return m.openDirectMessage(discord.UserID(msg))
+	case directMessageOpenedMsg:
+		return m.onDirectMessageOpened(discord.Channel(msg))
+	case markReadMsg:
+		return m.guildsTree.markRead(msg)
+	case notificationSettingsMsg:
+		return m.updateNotificationSettings(msg)
+	case muteMsg:
+		return m.mute(msg)
+	case commandsLoadedMsg:
+		return msg.composer.onCommandsLoaded(msg)
 	}
 	return m.Layers.Update(msg)
 }
@@ -41,15 +41,81 @@ func listen(events <-chan gateway.Event) tview.Cmd {
 type channelLoadedMsg struct {
 	Channel  discord.Channel
 	Messages []discord.Message
+	// LastReadID is the read marker before the channel was marked as read.
+	LastReadID discord.MessageID
+	// TargetID is the message to select when a window of history around it
+	// was loaded instead of the latest messages.
+	TargetID discord.MessageID
+	// Detached is set when the loaded window does not reach the latest
+	// message of the channel.
+	Detached bool
+}
+
+// messageLinkMsg requests navigation to a Discord message link.
+type messageLinkMsg struct {
+	Link messageLink
+}
+
+type closePromptMsg struct{}
+
+// imageLoadedMsg reports that the image at URL was downloaded and can be
+// previewed.
+type imageLoadedMsg struct {
+	URL string
 }
 
 type olderMessagesLoadedMsg struct {
 	ChannelID discord.ChannelID
 	Older     []discord.Message
+	// SelectUnread moves the cursor to the first unread message once the
+	// older messages are prepended.
+	SelectUnread bool
+}
+
+type newerMessagesLoadedMsg struct {
+	ChannelID discord.ChannelID
+	// Newer is sorted from oldest to newest.
+	Newer []discord.Message
+	// Live is set when the page reaches the latest message of the channel.
+	Live bool
+}
+
+// presentLoadedMsg replaces a detached window with the latest messages.
+type presentLoadedMsg struct {
+	ChannelID discord.ChannelID
+	Messages  []discord.Message
+}
+
+type threadLoadedMsg struct {
+	Thread   discord.Channel
+	Messages []discord.Message
+	// TargetID and Detached are as in channelLoadedMsg.
+	TargetID discord.MessageID
+	Detached bool
+}
+
+type pinsLoadedMsg struct {
+	Channel  discord.Channel
+	Messages []discord.Message
 }
 
 type deleteMessageMsg discord.Message
 
+type deleteMessagesMsg []discord.Message
+
+// selectMenuMsg opens the options of a select menu in the component picker.
+type selectMenuMsg selectMenu
+
+type toggleSelectOptionMsg string
+
+// mentionUserMsg adds a mention of the user to the composer.
+type mentionUserMsg discord.User
+
+// directMessageMsg opens the DM with the user.
+type directMessageMsg discord.UserID
+
+type directMessageOpenedMsg discord.Channel
+
 type LogoutMsg struct{}
 
 func logout() tview.Cmd {
@@ -0,0 +1,281 @@
+package chat
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/api"
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/arikawa/v3/utils/httputil"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/tree"
+	"github.com/gdamore/tcell/v3"
+)
+
+type notificationLevel int
+
+const (
+	notifyAll notificationLevel = iota
+	notifyMentions
+	notifyNothing
+	// notifyDefault makes a channel follow the level of its guild.
+	notifyDefault
+)
+
+// muteForever is the time window of mutes that do not end.
+const muteForever = -1
+
+var muteDurations = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"15 minutes", 15 * time.Minute},
+	{"1 hour", time.Hour},
+	{"8 hours", 8 * time.Hour},
+	{"24 hours", 24 * time.Hour},
+	{"Forever", 0},
+}
+
+// mutedStyle replaces the unread style of muted nodes.
+var mutedStyle = tcell.StyleDefault.Dim(true)
+
+type muteConfig struct {
+	SelectedTimeWindow int        `json:"selected_time_window"`
+	EndTime            *time.Time `json:"end_time"`
+}
+
+// notificationSettings holds the settings shared by guilds and channels; nil
+// fields are left unchanged.
+type notificationSettings struct {
+	Muted                *bool              `json:"muted,omitempty"`
+	MuteConfig           *muteConfig        `json:"mute_config,omitempty"`
+	MessageNotifications *notificationLevel `json:"message_notifications,omitempty"`
+}
+
+type guildNotificationSettings struct {
+	notificationSettings
+	SuppressEveryone *bool                                      `json:"suppress_everyone,omitempty"`
+	SuppressRoles    *bool                                      `json:"suppress_roles,omitempty"`
+	ChannelOverrides map[discord.ChannelID]notificationSettings `json:"channel_overrides,omitempty"`
+}
+
+// notificationTarget is the guild, or the guild or DM channel, whose settings
+// the modal changes.
+type notificationTarget struct {
+	name      string
+	guildID   discord.GuildID
+	channelID discord.ChannelID
+}
+
+// settings applies the settings to the channel when the target is one, or to
+// the guild otherwise.
+func (t notificationTarget) settings(settings notificationSettings) guildNotificationSettings {
+	if t.channelID.IsValid() {
+		return guildNotificationSettings{
+			ChannelOverrides: map[discord.ChannelID]notificationSettings{t.channelID: settings},
+		}
+	}
+	return guildNotificationSettings{notificationSettings: settings}
+}
+
+func (t notificationTarget) msg(settings guildNotificationSettings) notificationSettingsMsg {
+	return notificationSettingsMsg{guildID: t.guildID, settings: settings}
+}
+
+type notificationSettingsMsg struct {
+	// guildID is null for DM channels.
+	guildID  discord.GuildID
+	settings guildNotificationSettings
+}
+
+// channelMuted reports whether the channel, or the category holding it, is
+// muted.
+func (gt *guildsTree) channelMuted(channelID discord.ChannelID) bool {
+	return gt.state.MutedState.Channel(channelID) || gt.state.MutedState.Category(channelID)
+}
+
+func (gt *guildsTree) guildMuted(guildID discord.GuildID) bool {
+	return gt.state.MutedState.Guild(guildID, false)
+}
+
+// showNotificationSettings opens the settings of the guild or channel under
+// the cursor. Each choice opens a modal of its own.
+func (gt *guildsTree) showNotificationSettings() tview.Cmd {
+	node := gt.CurrentNode()
+	if node == nil {
+		return nil
+	}
+
+	target := notificationTarget{name: gt.nodeLabel(node)}
+	var muted bool
+	switch ref := node.Reference().(type) {
+	case discord.GuildID:
+		target.guildID = ref
+		muted = gt.guildMuted(ref)
+	case discord.ChannelID:
+		channel, err := gt.state.Cabinet.Channel(ref)
+		if err != nil {
+			slog.Error("failed to get channel from state", "err", err, "channel_id", ref)
+			return nil
+		}
+		// Threads follow their parent channel.
+		if isThread(channel.Type) {
+			return nil
+		}
+		target.guildID = channel.GuildID
+		target.channelID = ref
+		muted = gt.state.MutedState.Channel(ref)
+	default:
+		return nil
+	}
+
+	text := "Notification settings for " + target.name + "."
+	var buttons []ui.ModalButton
+	if muted {
+		text += "\nMuted."
+		unmuted := false
+		buttons = append(buttons, ui.ModalButton{
+			Label:  "Unmute",
+			Result: target.msg(target.settings(notificationSettings{Muted: &unmuted})),
+		})
+	} else {
+		buttons = append(buttons, ui.ModalButton{Label: "Mute", Result: muteModal(target)})
+	}
+	buttons = append(buttons, ui.ModalButton{Label: "Notifications", Result: notificationLevelModal(target)})
+	if !target.channelID.IsValid() {
+		buttons = append(buttons, ui.ModalButton{Label: "Suppress", Result: suppressModal(target)})
+	}
+	buttons = append(buttons, ui.ModalButton{Label: "Close"})
+	return ui.ShowModal(text, buttons...)
+}
+
+func muteModal(target notificationTarget) ui.ModalMsg {
+	buttons := make([]ui.ModalButton, 0, len(muteDurations)+1)
+	for _, d := range muteDurations {
+		buttons = append(buttons, ui.ModalButton{Label: d.label, Result: muteMsg{target: target, duration: d.duration}})
+	}
+	buttons = append(buttons, ui.ModalButton{Label: "Cancel"})
+	return ui.ModalMsg{Text: "Mute " + target.name + " for how long?", Buttons: buttons}
+}
+
+// muteMsg mutes the target for the duration, or forever when it is 0. The end
+// of the mute is computed once the duration is picked.
+type muteMsg struct {
+	target   notificationTarget
+	duration time.Duration
+}
+
+func (m *Model) mute(msg muteMsg) tview.Cmd {
+	settings := muteSettings(msg.duration, time.Now())
+	return m.updateNotificationSettings(msg.target.msg(msg.target.settings(settings)))
+}
+
+// muteSettings mutes for the duration from now, or forever when it is 0.
+func muteSettings(duration time.Duration, now time.Time) notificationSettings {
+	muted := true
+	config := &muteConfig{SelectedTimeWindow: muteForever}
+	if duration > 0 {
+		end := now.Add(duration).UTC()
+		config = &muteConfig{SelectedTimeWindow: int(duration.Seconds()), EndTime: &end}
+	}
+	return notificationSettings{Muted: &muted, MuteConfig: config}
+}
+
+var notificationLevels = []struct {
+	label string
+	level notificationLevel
+}{
+	{"All messages", notifyAll},
+	{"Mentions", notifyMentions},
+	{"Nothing", notifyNothing},
+	{"Guild default", notifyDefault},
+}
+
+func notificationLevelModal(target notificationTarget) ui.ModalMsg {
+	buttons := make([]ui.ModalButton, 0, len(notificationLevels)+1)
+	for _, l := range notificationLevels {
+		// Only channels can follow another level.
+		if l.level == notifyDefault && !target.channelID.IsValid() {
+			continue
+		}
+		settings := notificationSettings{MessageNotifications: &l.level}
+		buttons = append(buttons, ui.ModalButton{Label: l.label, Result: target.msg(target.settings(settings))})
+	}
+	buttons = append(buttons, ui.ModalButton{Label: "Cancel"})
+	return ui.ModalMsg{Text: "Notify about which messages in " + target.name + "?", Buttons: buttons}
+}
+
+func suppressModal(target notificationTarget) ui.ModalMsg {
+	suppress := func(everyone, roles bool) tview.Msg {
+		return target.msg(guildNotificationSettings{SuppressEveryone: &everyone, SuppressRoles: &roles})
+	}
+	return ui.ModalMsg{
+		Text: "Suppress which mentions in " + target.name + "?",
+		Buttons: []ui.ModalButton{
+			{Label: "@everyone and @here", Result: suppress(true, false)},
+			{Label: "Roles", Result: suppress(false, true)},
+			{Label: "Both", Result: suppress(true, true)},
+			{Label: "Neither", Result: suppress(false, false)},
+			{Label: "Cancel"},
+		},
+	}
+}
+
+// nodeLabel returns the text of the node without its badge.
+func (gt *guildsTree) nodeLabel(node *tree.Node) string {
+	var b strings.Builder
+	for _, segment := range node.Line()[:gt.labelLen(node)] {
+		b.WriteString(segment.Text)
+	}
+	return b.String()
+}
+
+// updateNotificationSettings patches the settings of the guild, or of the DMs
+// when the guild ID is null. The tree is restyled once the gateway sends the
+// new settings back.
+func (m *Model) updateNotificationSettings(msg notificationSettingsMsg) tview.Cmd {
+	guild := "@me"
+	if msg.guildID.IsValid() {
+		guild = msg.guildID.String()
+	}
+	url := api.EndpointUsers + "@me/guilds/" + guild + "/settings"
+	return func() tview.Msg {
+		if err := m.state.FastRequest("PATCH", url, httputil.WithJSONBody(msg.settings)); err != nil {
+			slog.Error("failed to update notification settings", "err", err, "guild_id", msg.guildID)
+			return ui.ModalMsg{Text: "Failed to update notification settings: " + err.Error(), Buttons: []ui.ModalButton{{Label: "Close"}}}
+		}
+		return nil
+	}
+}
+
+// onNotificationSettingsUpdate restyles the guild, or the DMs when the guild
+// ID is null, and the channels under it.
+func (gt *guildsTree) onNotificationSettingsUpdate(guildID discord.GuildID) {
+	parent := gt.dmRootNode
+	if guildID.IsValid() {
+		parent = gt.guildNodeByID[guildID]
+		if parent != nil {
+			gt.setNodeLineStyle(parent, gt.guildNodeStyle(guildID))
+		}
+	}
+	if parent == nil {
+		return
+	}
+
+	parent.Walk(func(node, _ *tree.Node) bool {
+		channelID, ok := node.Reference().(discord.ChannelID)
+		if !ok {
+			return true
+		}
+		channel, err := gt.state.Cabinet.Channel(channelID)
+		if err != nil {
+			return true
+		}
+		gt.setNodeLineStyle(node, gt.channelNodeStyle(*channel))
+		gt.setNodeBadge(node, gt.channelMentions(channelID))
+		return true
+	})
+	gt.updateBadges(guildID, 0)
+}
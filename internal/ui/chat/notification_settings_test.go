@@ -0,0 +1,26 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMuteSettings(t *testing.T) {
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	settings := muteSettings(time.Hour, now)
+	if settings.Muted == nil || !*settings.Muted {
+		t.Fatal("muteSettings(1h) does not mute")
+	}
+	if got, want := settings.MuteConfig.SelectedTimeWindow, 3600; got != want {
+		t.Errorf("muteSettings(1h) window = %d, want %d", got, want)
+	}
+	if want := time.Date(2024, time.May, 1, 11, 0, 0, 0, time.UTC); settings.MuteConfig.EndTime == nil || !settings.MuteConfig.EndTime.Equal(want) || settings.MuteConfig.EndTime.Location() != time.UTC {
+		t.Errorf("muteSettings(1h) end = %v, want %v", settings.MuteConfig.EndTime, want)
+	}
+
+	settings = muteSettings(0, now)
+	if settings.MuteConfig.SelectedTimeWindow != muteForever || settings.MuteConfig.EndTime != nil {
+		t.Errorf("muteSettings(0) = %+v, want a mute with no end", *settings.MuteConfig)
+	}
+}
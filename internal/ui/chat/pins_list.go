@@ -0,0 +1,154 @@
+package chat
+
+import (
+	"log/slog"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/help"
+	"github.com/ayn2op/tview/keybind"
+	"github.com/ayn2op/tview/layers"
+	"github.com/ayn2op/tview/list"
+)
+
+// pinsList is an overlay listing the pinned messages of a channel. Pins are
+// rendered like messages in the messages list.
+type pinsList struct {
+	*list.Model
+	cfg      *config.Config
+	chat     *Model
+	messages []discord.Message
+}
+
+var (
+	_ tview.Model = (*pinsList)(nil)
+	_ help.KeyMap = (*pinsList)(nil)
+)
+
+func newPinsList(cfg *config.Config, chat *Model) *pinsList {
+	pl := &pinsList{
+		Model: list.NewModel(),
+		cfg:   cfg,
+		chat:  chat,
+	}
+
+	ui.ConfigureBox(pl.Box, &cfg.Theme)
+	ui.UpdateBoxFocus(pl.Box, &cfg.Theme, tview.FocusMsg{})
+	pl.SetSelectedStyle(cfg.Theme.MessagesList.SelectedMessageStyle.Style)
+	pl.SetKeybinds(list.Keybinds{
+		SelectUp:     cfg.Keybinds.Picker.SelectUp.Keybind,
+		SelectDown:   cfg.Keybinds.Picker.SelectDown.Keybind,
+		SelectTop:    cfg.Keybinds.Picker.SelectTop.Keybind,
+		SelectBottom: cfg.Keybinds.Picker.SelectBottom.Keybind,
+	})
+	pl.SetScrollBarVisibility(cfg.Theme.ScrollBar.Visibility.ScrollBarVisibility)
+	pl.SetScrollBar(tview.NewScrollBar().
+		SetTrackStyle(cfg.Theme.ScrollBar.TrackStyle.Style).
+		SetThumbStyle(cfg.Theme.ScrollBar.ThumbStyle.Style).
+		SetGlyphSet(cfg.Theme.ScrollBar.GlyphSet.GlyphSet))
+	return pl
+}
+
+// setMessages shows the pins, newest first as returned by Discord.
+func (pl *pinsList) setMessages(channel discord.Channel, messages []discord.Message) {
+	pl.messages = messages
+	pl.SetTitle("Pinned messages in " + ui.ChannelToString(channel, pl.cfg.Icons, pl.chat.state))
+	pl.SetBuilder(pl.buildItem)
+	if len(messages) == 0 {
+		pl.SetCursor(-1)
+		return
+	}
+	pl.SetCursor(0)
+}
+
+func (pl *pinsList) buildItem(index int) list.Item {
+	if index < 0 || index >= len(pl.messages) {
+		return nil
+	}
+
+	lines := pl.chat.messagesList.renderMessage(pl.messages[index], pl.cfg.Theme.MessagesList.MessageStyle.Style)
+	return tview.NewTextView().
+		SetWrap(true).
+		SetWordWrap(true).
+		SetLines(lines)
+}
+
+func (pl *pinsList) Update(msg tview.Msg) tview.Cmd {
+	switch msg := msg.(type) {
+	case tview.KeyMsg:
+		switch {
+		case keybind.Matches(msg, pl.cfg.Keybinds.Picker.Cancel.Keybind):
+			return pl.chat.closePins()
+		case keybind.Matches(msg, pl.cfg.Keybinds.Picker.Select.Keybind):
+			index := pl.Cursor()
+			if index < 0 || index >= len(pl.messages) {
+				return nil
+			}
+			message := pl.messages[index]
+			return tview.Sequence(pl.chat.closePins(), pl.chat.navigateToPin(message))
+		}
+	}
+	return pl.Model.Update(msg)
+}
+
+func (pl *pinsList) ShortHelp() []keybind.Keybind {
+	cfg := pl.cfg.Keybinds.Picker
+	return []keybind.Keybind{cfg.SelectUp.Keybind, cfg.SelectDown.Keybind, cfg.Select.Keybind, cfg.Cancel.Keybind}
+}
+
+func (pl *pinsList) FullHelp() [][]keybind.Keybind {
+	cfg := pl.cfg.Keybinds.Picker
+	return [][]keybind.Keybind{
+		{cfg.SelectUp.Keybind, cfg.SelectDown.Keybind, cfg.SelectTop.Keybind, cfg.SelectBottom.Keybind},
+		{cfg.Select.Keybind, cfg.Cancel.Keybind},
+	}
+}
+
+func (m *Model) showPins(channel discord.Channel) tview.Cmd {
+	return func() tview.Msg {
+		messages, err := m.state.PinnedMessages(channel.ID)
+		if err != nil {
+			slog.Error("failed to get pinned messages", "err", err, "channel_id", channel.ID)
+			return nil
+		}
+		return pinsLoadedMsg{Channel: channel, Messages: messages}
+	}
+}
+
+func (m *Model) openPins(channel discord.Channel, messages []discord.Message) tview.Cmd {
+	if len(messages) == 0 {
+		return ui.ShowModal("This channel has no pinned messages.", ui.ModalButton{Label: "Close"})
+	}
+
+	m.pinsList.setMessages(channel, messages)
+	m.AddLayer(
+		ui.Centered(m.pinsList, m.cfg.Picker.Width, m.cfg.Picker.Height),
+		layers.WithName(pinsListLayerName),
+		layers.WithResize(true),
+		layers.WithVisible(true),
+		layers.WithOverlay(),
+	).SendToFront(pinsListLayerName)
+	return tview.SetFocus(m.pinsList)
+}
+
+func (m *Model) closePins() tview.Cmd {
+	m.RemoveLayer(pinsListLayerName)
+	return tview.SetFocus(m.activeMessagesList())
+}
+
+// navigateToPin selects the pinned message in the thread pane when it was
+// pinned in the thread shown there, and in the messages list otherwise.
+func (m *Model) navigateToPin(message discord.Message) tview.Cmd {
+	thread, ok := m.threadPane.thread()
+	if !ok || thread.ID != message.ChannelID {
+		return m.navigateToMessage(message.ChannelID, message.ID)
+	}
+
+	ml := m.threadPane.messagesList
+	if ml.highlightMessage(message.ID) {
+		return tview.SetFocus(ml)
+	}
+	return m.openThreadAround(*thread, message.ID)
+}
@@ -0,0 +1,238 @@
+package chat
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/api"
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/arikawa/v3/utils/httputil"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/layers"
+	"github.com/gdamore/tcell/v3"
+)
+
+const pollBarWidth = 20
+
+// pollVotes returns the vote count per answer, the answers voted for by the
+// user and the total number of votes.
+func pollVotes(poll discord.Poll) (counts map[int]int, mine map[int]bool, total int) {
+	counts = make(map[int]int, len(poll.Answers))
+	mine = make(map[int]bool)
+	if poll.Results == nil {
+		return counts, mine, 0
+	}
+
+	for _, count := range poll.Results.AnswerCounts {
+		counts[count.ID] = count.Count
+		total += count.Count
+		if count.MeVoted {
+			mine[count.ID] = true
+		}
+	}
+	return counts, mine, total
+}
+
+func pollClosed(poll discord.Poll) bool {
+	if poll.Results != nil && poll.Results.IsFinalized {
+		return true
+	}
+	return poll.Expiry.IsValid() && !poll.Expiry.Time().After(time.Now())
+}
+
+func (ml *messagesList) drawPoll(builder *tview.LineBuilder, poll discord.Poll, baseStyle tcell.Style) {
+	theme := ml.cfg.Theme.MessagesList
+	counts, mine, total := pollVotes(poll)
+	closed := pollClosed(poll)
+
+	// The winning answers are highlighted once the poll has ended.
+	winning := 0
+	if closed {
+		for _, count := range counts {
+			winning = max(winning, count)
+		}
+	}
+
+	builder.NewLine()
+	builder.Write(poll.Question.Text, baseStyle.Bold(true))
+
+	for _, answer := range poll.Answers {
+		count := counts[answer.AnswerID]
+		percent := 0
+		if total > 0 {
+			percent = count * 100 / total
+		}
+		filled := percent * pollBarWidth / 100
+
+		style := baseStyle
+		mark := "  "
+		if mine[answer.AnswerID] {
+			style = tview.MergeStyle(baseStyle, theme.PollOwnVoteStyle.Style)
+			mark = "✓ "
+		}
+		if closed && winning > 0 && count == winning {
+			style = style.Bold(true)
+		}
+
+		builder.NewLine()
+		builder.Write(mark+answer.PollMedia.Text, style)
+		builder.NewLine()
+		barStyle := tview.MergeStyle(baseStyle, theme.PollBarStyle.Style)
+		builder.Write("  "+strings.Repeat("█", filled), barStyle)
+		builder.Write(strings.Repeat("░", pollBarWidth-filled), barStyle.Dim(true))
+		builder.Write(" "+strconv.Itoa(count)+" ("+strconv.Itoa(percent)+"%)", baseStyle.Dim(true))
+	}
+
+	votes := strconv.Itoa(total) + " votes"
+	if total == 1 {
+		votes = "1 vote"
+	}
+	switch {
+	case poll.Results != nil && poll.Results.IsFinalized:
+		votes += " · Final results"
+	case closed:
+		votes += " · Poll closed"
+	case poll.Expiry.IsValid():
+		end := poll.Expiry.Time().In(time.Local)
+		votes += " · Ends " + end.Format(ml.cfg.DateSeparator.Format) + " " + end.Format(ml.cfg.Timestamps.Format)
+	}
+	if poll.AllowMultiselect && !closed {
+		votes += " · Select one or more answers"
+	}
+	builder.NewLine()
+	builder.Write(votes, baseStyle.Dim(true))
+}
+
+func (ml *messagesList) showPollPicker() tview.Cmd {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok || selectedMessage.Poll == nil || pollClosed(*selectedMessage.Poll) {
+		return nil
+	}
+
+	ml.pollMessageID = selectedMessage.ID
+	ml.pollPicker.SetPoll(*selectedMessage.Poll)
+	ml.chat.
+		AddLayer(
+			ui.Centered(ml.pollPicker, ml.cfg.Picker.Width, ml.cfg.Picker.Height),
+			layers.WithName(pollPickerLayerName),
+			layers.WithResize(true),
+			layers.WithVisible(true),
+			layers.WithOverlay(),
+		).
+		SendToFront(pollPickerLayerName)
+	return tview.SetFocus(ml.pollPicker)
+}
+
+// vote toggles the user's vote for the answer of the poll the picker was
+// opened for.
+func (ml *messagesList) vote(answerID int) tview.Cmd {
+	index := slices.IndexFunc(ml.messages, func(m discord.Message) bool {
+		return m.ID == ml.pollMessageID
+	})
+	if index < 0 || ml.messages[index].Poll == nil {
+		return nil
+	}
+
+	message := ml.messages[index]
+	return ml.putPollVotes(message.ChannelID, message.ID, pollVoteAnswers(*message.Poll, answerID))
+}
+
+// pollVoteAnswers returns the answers the user votes for after toggling the
+// answer. Single-choice polls replace the previous vote.
+func pollVoteAnswers(poll discord.Poll, answerID int) []int {
+	_, mine, _ := pollVotes(poll)
+
+	var answerIDs []int
+	switch {
+	case mine[answerID]:
+		delete(mine, answerID)
+		if poll.AllowMultiselect {
+			for id := range mine {
+				answerIDs = append(answerIDs, id)
+			}
+		}
+	case poll.AllowMultiselect:
+		for id := range mine {
+			answerIDs = append(answerIDs, id)
+		}
+		answerIDs = append(answerIDs, answerID)
+	default:
+		answerIDs = []int{answerID}
+	}
+	slices.Sort(answerIDs)
+	return answerIDs
+}
+
+func (ml *messagesList) removePollVotes() tview.Cmd {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok || selectedMessage.Poll == nil || pollClosed(*selectedMessage.Poll) {
+		return nil
+	}
+	return ml.putPollVotes(selectedMessage.ChannelID, selectedMessage.ID, nil)
+}
+
+// putPollVotes replaces the user's votes; an empty list removes them.
+func (ml *messagesList) putPollVotes(channelID discord.ChannelID, messageID discord.MessageID, answerIDs []int) tview.Cmd {
+	ids := make([]string, len(answerIDs))
+	for i, id := range answerIDs {
+		ids[i] = strconv.Itoa(id)
+	}
+
+	body := struct {
+		AnswerIDs []string `json:"answer_ids"`
+	}{ids}
+	url := api.EndpointChannels + channelID.String() + "/polls/" + messageID.String() + "/answers/@me"
+	return func() tview.Msg {
+		if err := ml.chat.state.FastRequest("PUT", url, httputil.WithJSONBody(body)); err != nil {
+			slog.Error("failed to vote in poll", "err", err, "channel_id", channelID, "message_id", messageID)
+		}
+		return nil
+	}
+}
+
+// onPollVote applies a vote add or remove event to the loaded poll.
+func (m *Model) onPollVote(channelID discord.ChannelID, messageID discord.MessageID, userID discord.UserID, answerID int, add bool) {
+	delta := -1
+	if add {
+		delta = 1
+	}
+	me := m.isMe(userID)
+
+	for _, ml := range m.messagesListsFor(channelID) {
+		index := slices.IndexFunc(ml.messages, func(message discord.Message) bool {
+			return message.ID == messageID
+		})
+		if index < 0 || ml.messages[index].Poll == nil {
+			continue
+		}
+
+		message := ml.messages[index]
+		poll := *message.Poll
+		var results discord.PollResults
+		if poll.Results != nil {
+			results = *poll.Results
+		}
+		counts := slices.Clone(results.AnswerCounts)
+
+		i := slices.IndexFunc(counts, func(count discord.PollAnswerCount) bool {
+			return count.ID == answerID
+		})
+		if i < 0 {
+			counts = append(counts, discord.PollAnswerCount{ID: answerID})
+			i = len(counts) - 1
+		}
+		counts[i].Count = max(counts[i].Count+delta, 0)
+		if me {
+			counts[i].MeVoted = add
+		}
+
+		results.AnswerCounts = counts
+		poll.Results = &results
+		message.Poll = &poll
+		ml.setMessage(index, message)
+	}
+}
@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+)
+
+func TestPollVotes(t *testing.T) {
+	tests := []struct {
+		name       string
+		results    *discord.PollResults
+		wantCounts map[int]int
+		wantMine   map[int]bool
+		wantTotal  int
+	}{
+		{"no results", nil, map[int]int{}, map[int]bool{}, 0},
+		{
+			"votes",
+			&discord.PollResults{AnswerCounts: []discord.PollAnswerCount{
+				{ID: 1, Count: 3},
+				{ID: 2, Count: 2, MeVoted: true},
+				{ID: 3, Count: 0},
+			}},
+			map[int]int{1: 3, 2: 2, 3: 0},
+			map[int]bool{2: true},
+			5,
+		},
+	}
+
+	for _, test := range tests {
+		counts, mine, total := pollVotes(discord.Poll{Results: test.results})
+		if !maps.Equal(counts, test.wantCounts) {
+			t.Errorf("%s: counts = %v, want %v", test.name, counts, test.wantCounts)
+		}
+		if !maps.Equal(mine, test.wantMine) {
+			t.Errorf("%s: mine = %v, want %v", test.name, mine, test.wantMine)
+		}
+		if total != test.wantTotal {
+			t.Errorf("%s: total = %d, want %d", test.name, total, test.wantTotal)
+		}
+	}
+}
+
+func TestPollVoteAnswers(t *testing.T) {
+	results := func(mine ...int) *discord.PollResults {
+		var counts []discord.PollAnswerCount
+		for _, id := range mine {
+			counts = append(counts, discord.PollAnswerCount{ID: id, Count: 1, MeVoted: true})
+		}
+		return &discord.PollResults{AnswerCounts: counts}
+	}
+
+	tests := []struct {
+		name     string
+		poll     discord.Poll
+		answerID int
+		want     []int
+	}{
+		{"first vote", discord.Poll{}, 1, []int{1}},
+		{"replace single choice", discord.Poll{Results: results(1)}, 2, []int{2}},
+		{"remove single choice", discord.Poll{Results: results(1)}, 1, nil},
+		{"add multiple choice", discord.Poll{AllowMultiselect: true, Results: results(3, 1)}, 2, []int{1, 2, 3}},
+		{"remove multiple choice", discord.Poll{AllowMultiselect: true, Results: results(1, 2, 3)}, 2, []int{1, 3}},
+		{"remove last multiple choice", discord.Poll{AllowMultiselect: true, Results: results(1)}, 1, nil},
+	}
+
+	for _, test := range tests {
+		if got := pollVoteAnswers(test.poll, test.answerID); !slices.Equal(got, test.want) {
+			t.Errorf("%s: pollVoteAnswers(%d) = %v, want %v", test.name, test.answerID, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package pollpicker
+
+import (
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/picker"
+)
+
+type Model struct {
+	*picker.Model
+}
+
+func NewModel(cfg *config.Config) *Model {
+	m := &Model{Model: picker.NewModel()}
+	ui.ConfigurePicker(m.Model, cfg, "Vote")
+	return m
+}
+
+var _ tview.Model = (*Model)(nil)
+
+func (m *Model) Update(msg tview.Msg) tview.Cmd {
+	switch msg := msg.(type) {
+	case picker.SelectedMsg:
+		answerID, ok := msg.Reference.(int)
+		if !ok {
+			return nil
+		}
+		return func() tview.Msg { return SelectedMsg{AnswerID: answerID} }
+	case picker.CancelMsg:
+		return func() tview.Msg { return CancelMsg{} }
+	}
+	return m.Model.Update(msg)
+}
+
+// SetPoll lists the answers of the poll, marking the ones voted for.
+// Selecting an answer toggles the vote for it.
+func (m *Model) SetPoll(poll discord.Poll) {
+	voted := make(map[int]bool)
+	if poll.Results != nil {
+		for _, count := range poll.Results.AnswerCounts {
+			voted[count.ID] = count.MeVoted
+		}
+	}
+
+	items := make(picker.Items, 0, len(poll.Answers))
+	for _, answer := range poll.Answers {
+		mark := "[ ] "
+		if voted[answer.AnswerID] {
+			mark = "[x] "
+		}
+		text := answer.PollMedia.Text
+		items = append(items, picker.Item{Text: mark + text, FilterText: text, Reference: answer.AnswerID})
+	}
+
+	m.SetTitle(poll.Question.Text)
+	m.SetItems(items)
+}
@@ -0,0 +1,7 @@
+package pollpicker
+
+type SelectedMsg struct {
+	AnswerID int
+}
+
+type CancelMsg struct{}
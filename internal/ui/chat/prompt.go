@@ -0,0 +1,63 @@
+package chat
+
+import (
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/layers"
+)
+
+const promptHeight = 7
+
+// prompt is a single-field form, such as the link or thread name prompt.
+// submit is called with the entered text once the form is submitted.
+type prompt struct {
+	*tview.Form
+	submit func(text string) tview.Cmd
+}
+
+func newPrompt(cfg *config.Config) *prompt {
+	form := tview.NewForm().
+		AddInputField("", "", 0).
+		AddButton("OK")
+	ui.ConfigureBox(form.Box, &cfg.Theme)
+	ui.UpdateBoxFocus(form.Box, &cfg.Theme, tview.FocusMsg{})
+	return &prompt{Form: form}
+}
+
+var _ tview.Model = (*prompt)(nil)
+
+func (p *prompt) Update(msg tview.Msg) tview.Cmd {
+	switch msg.(type) {
+	case tview.FormSubmitMsg:
+		text := p.GetFormItem(0).(*tview.InputField).Text()
+		return tview.Sequence(
+			func() tview.Msg { return closePromptMsg{} },
+			p.submit(text),
+		)
+	case tview.FormCancelMsg:
+		return func() tview.Msg { return closePromptMsg{} }
+	}
+	return p.Form.Update(msg)
+}
+
+func (m *Model) openPrompt(title, label string, submit func(text string) tview.Cmd) tview.Cmd {
+	m.prompt.submit = submit
+	m.prompt.SetTitle(title)
+	m.prompt.GetFormItem(0).(*tview.InputField).
+		SetLabel(label).
+		SetText("")
+	m.AddLayer(
+		ui.Centered(m.prompt, m.cfg.Picker.Width, promptHeight),
+		layers.WithName(promptLayerName),
+		layers.WithResize(true),
+		layers.WithVisible(true),
+		layers.WithOverlay(),
+	).SendToFront(promptLayerName)
+	return tview.SetFocus(m.prompt)
+}
+
+func (m *Model) closePrompt() tview.Cmd {
+	m.RemoveLayer(promptLayerName)
+	return tview.SetFocus(m.mainFlex)
+}
@@ -0,0 +1,241 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/api"
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/discordo/internal/ui/chat/searchpicker"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/layers"
+)
+
+const searchPromptHeight = 7
+
+type searchResponse struct {
+	TotalResults int `json:"total_results"`
+	// Each group holds the hit, optionally surrounded by context messages.
+	Messages [][]searchMessage `json:"messages"`
+}
+
+// searchMessage is a message of a search result group; Hit marks the one
+// that matched.
+type searchMessage struct {
+	discord.Message
+	Hit bool `json:"hit"`
+}
+
+// UnmarshalJSON decodes Hit on its own, so that it is not lost to how the
+// message decodes itself.
+func (m *searchMessage) UnmarshalJSON(data []byte) error {
+	var hit struct {
+		Hit bool `json:"hit"`
+	}
+	if err := json.Unmarshal(data, &hit); err != nil {
+		return err
+	}
+	m.Hit = hit.Hit
+	return json.Unmarshal(data, &m.Message)
+}
+
+// searchHit returns the message of the group that matched, or the first one
+// when none is marked.
+func searchHit(group []searchMessage) discord.Message {
+	if i := slices.IndexFunc(group, func(m searchMessage) bool { return m.Hit }); i >= 0 {
+		return group[i].Message
+	}
+	return group[0].Message
+}
+
+type searchResultsMsg struct {
+	Results []searchpicker.Result
+	Total   int
+}
+
+func (m *Model) toggleSearch() tview.Cmd {
+	if m.HasLayer(searchPromptLayerName) || m.HasLayer(searchResultsLayerName) {
+		return m.closeSearch()
+	}
+	return m.openSearchPrompt()
+}
+
+func (m *Model) openSearchPrompt() tview.Cmd {
+	if _, ok := m.SelectedChannel(); !ok {
+		return nil
+	}
+
+	m.searchPrompt.Reset()
+	m.AddLayer(
+		ui.Centered(m.searchPrompt, m.cfg.Picker.Width, searchPromptHeight),
+		layers.WithName(searchPromptLayerName),
+		layers.WithResize(true),
+		layers.WithVisible(true),
+		layers.WithOverlay(),
+	).SendToFront(searchPromptLayerName)
+	return tview.SetFocus(m.searchPrompt)
+}
+
+func (m *Model) openSearchResults(results []searchpicker.Result, total int) tview.Cmd {
+	m.RemoveLayer(searchPromptLayerName)
+	m.searchPicker.SetResults(results, total)
+	m.AddLayer(
+		ui.Centered(m.searchPicker, m.cfg.Picker.Width, m.cfg.Picker.Height),
+		layers.WithName(searchResultsLayerName),
+		layers.WithResize(true),
+		layers.WithVisible(true),
+		layers.WithOverlay(),
+	).SendToFront(searchResultsLayerName)
+	return tview.SetFocus(m.searchPicker)
+}
+
+func (m *Model) closeSearch() tview.Cmd {
+	m.RemoveLayer(searchPromptLayerName)
+	m.RemoveLayer(searchResultsLayerName)
+	return tview.SetFocus(m.mainFlex)
+}
+
+// search queries the guild of the selected channel, or the channel itself
+// for direct messages.
+func (m *Model) search(query searchpicker.Query) tview.Cmd {
+	selectedChannel, ok := m.SelectedChannel()
+	if !ok {
+		return nil
+	}
+	channel := *selectedChannel
+
+	values, err := m.searchValues(channel, query)
+	if err != nil {
+		slog.Error("failed to build search query", "err", err)
+		return ui.ShowModal(err.Error(), ui.ModalButton{Label: "Close"})
+	}
+
+	endpoint := api.EndpointChannels + channel.ID.String()
+	if channel.GuildID.IsValid() {
+		endpoint = api.EndpointGuilds + channel.GuildID.String()
+	}
+	endpoint += "/messages/search?" + values.Encode()
+
+	return func() tview.Msg {
+		var resp searchResponse
+		if err := m.state.RequestJSON(&resp, "GET", endpoint); err != nil {
+			slog.Error("failed to search messages", "err", err, "channel_id", channel.ID, "guild_id", channel.GuildID)
+			return ui.ModalMsg{Text: "Failed to search messages: " + err.Error(), Buttons: []ui.ModalButton{{Label: "Close"}}}
+		}
+
+		results := make([]searchpicker.Result, 0, len(resp.Messages))
+		for _, group := range resp.Messages {
+			if len(group) == 0 {
+				continue
+			}
+
+			message := searchHit(group)
+			message.GuildID = channel.GuildID
+			results = append(results, searchpicker.Result{Message: message, Location: m.searchLocation(message.ChannelID)})
+		}
+		return searchResultsMsg{Results: results, Total: resp.TotalResults}
+	}
+}
+
+func (m *Model) searchLocation(channelID discord.ChannelID) string {
+	channel, err := m.state.Cabinet.Channel(channelID)
+	if err != nil {
+		return channelID.String()
+	}
+	return ui.ChannelToString(*channel, m.cfg.Icons, m.state)
+}
+
+// searchValues resolves the names in the query's filters against the state.
+func (m *Model) searchValues(channel discord.Channel, query searchpicker.Query) (url.Values, error) {
+	values := make(url.Values)
+	if query.Content != "" {
+		values.Set("content", query.Content)
+	}
+
+	for _, name := range query.From {
+		userID, err := m.resolveSearchUser(channel, name)
+		if err != nil {
+			return nil, err
+		}
+		values.Add("author_id", userID.String())
+	}
+
+	for _, name := range query.In {
+		if !channel.GuildID.IsValid() {
+			return nil, errors.New("in: is only supported in guilds")
+		}
+		channelID, err := m.resolveSearchChannel(channel.GuildID, name)
+		if err != nil {
+			return nil, err
+		}
+		values.Add("channel_id", channelID.String())
+	}
+
+	for _, has := range query.Has {
+		values.Add("has", has)
+	}
+
+	if !query.Before.IsZero() {
+		values.Set("max_id", discord.NewSnowflake(query.Before).String())
+	}
+	if !query.After.IsZero() {
+		// after: excludes the given day itself.
+		values.Set("min_id", discord.NewSnowflake(query.After.Add(24*time.Hour)).String())
+	}
+
+	return values, nil
+}
+
+func (m *Model) resolveSearchUser(channel discord.Channel, name string) (discord.UserID, error) {
+	if snowflake, err := discord.ParseSnowflake(name); err == nil {
+		return discord.UserID(snowflake), nil
+	}
+
+	if !channel.GuildID.IsValid() {
+		users := channel.DMRecipients
+		if me, err := m.state.Cabinet.Me(); err == nil {
+			users = append(users, *me)
+		}
+		for _, user := range users {
+			if strings.EqualFold(user.Username, name) || strings.EqualFold(user.DisplayName, name) {
+				return user.ID, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown user %q", name)
+	}
+
+	members, err := m.state.Cabinet.Members(channel.GuildID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get members: %w", err)
+	}
+	for _, member := range members {
+		if strings.EqualFold(member.User.Username, name) || strings.EqualFold(member.Nick, name) || strings.EqualFold(member.User.DisplayName, name) {
+			return member.User.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown member %q", name)
+}
+
+func (m *Model) resolveSearchChannel(guildID discord.GuildID, name string) (discord.ChannelID, error) {
+	if snowflake, err := discord.ParseSnowflake(name); err == nil {
+		return discord.ChannelID(snowflake), nil
+	}
+
+	channels, err := m.state.Cabinet.Channels(guildID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get channels: %w", err)
+	}
+	for _, channel := range channels {
+		if strings.EqualFold(channel.Name, name) {
+			return channel.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown channel %q", name)
+}
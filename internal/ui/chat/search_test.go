@@ -0,0 +1,31 @@
+package chat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+)
+
+func TestSearchHit(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want discord.MessageID
+	}{
+		{"alone", `[{"id":"1","hit":true}]`, 1},
+		{"context", `[{"id":"1"},{"id":"2"},{"id":"3","hit":true},{"id":"4"}]`, 3},
+		{"first", `[{"id":"1","hit":true},{"id":"2"},{"id":"3"}]`, 1},
+		{"unmarked", `[{"id":"1"},{"id":"2"},{"id":"3"}]`, 1},
+	}
+
+	for _, test := range tests {
+		var group []searchMessage
+		if err := json.Unmarshal([]byte(test.data), &group); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got := searchHit(group).ID; got != test.want {
+			t.Errorf("%s: searchHit() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
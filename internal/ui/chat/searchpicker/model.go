@@ -0,0 +1,75 @@
+package searchpicker
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/picker"
+)
+
+// Result is a single search hit.
+type Result struct {
+	Message discord.Message
+	// Location is the channel (and guild) the message was sent in.
+	Location string
+}
+
+type Model struct {
+	*picker.Model
+	cfg *config.Config
+}
+
+func NewModel(cfg *config.Config) *Model {
+	m := &Model{Model: picker.NewModel(), cfg: cfg}
+	ui.ConfigurePicker(m.Model, cfg, "Search results")
+	return m
+}
+
+var _ tview.Model = (*Model)(nil)
+
+func (m *Model) Update(msg tview.Msg) tview.Cmd {
+	switch msg := msg.(type) {
+	case picker.SelectedMsg:
+		message, ok := msg.Reference.(discord.Message)
+		if !ok {
+			return nil
+		}
+		return func() tview.Msg { return SelectedMsg{ChannelID: message.ChannelID, MessageID: message.ID} }
+	case picker.CancelMsg:
+		return func() tview.Msg { return CancelMsg{} }
+	}
+	return m.Model.Update(msg)
+}
+
+// SetResults lists the hits; total is the number of matches reported by
+// Discord, which may exceed the number of hits returned.
+func (m *Model) SetResults(results []Result, total int) {
+	items := make(picker.Items, 0, len(results))
+	for _, result := range results {
+		message := result.Message
+		content := strings.Join(strings.Fields(message.Content), " ")
+		if content == "" && len(message.Attachments) > 0 {
+			content = message.Attachments[0].Filename
+		}
+
+		var b strings.Builder
+		b.WriteString(message.Timestamp.Time().In(time.Local).Format(m.cfg.DateSeparator.Format))
+		b.WriteString(" ")
+		b.WriteString(result.Location)
+		b.WriteString(" ")
+		b.WriteString(message.Author.DisplayOrUsername())
+		b.WriteString(": ")
+		b.WriteString(content)
+
+		text := b.String()
+		items = append(items, picker.Item{Text: text, FilterText: text, Reference: message})
+	}
+
+	m.SetTitle("Search results (" + strconv.Itoa(len(results)) + " of " + strconv.Itoa(total) + ")")
+	m.SetItems(items)
+}
@@ -0,0 +1,15 @@
+package searchpicker
+
+import "github.com/ayn2op/arikawa/v3/discord"
+
+// QueryMsg is sent when the search prompt is submitted.
+type QueryMsg struct {
+	Query Query
+}
+
+type SelectedMsg struct {
+	ChannelID discord.ChannelID
+	MessageID discord.MessageID
+}
+
+type CancelMsg struct{}
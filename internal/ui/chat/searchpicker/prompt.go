@@ -0,0 +1,51 @@
+package searchpicker
+
+import (
+	"log/slog"
+
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+)
+
+// Prompt asks for the search query. Filters such as from:, in:, has:,
+// before: and after: are parsed out of the text.
+type Prompt struct {
+	*tview.Form
+}
+
+func NewPrompt(cfg *config.Config) *Prompt {
+	form := tview.NewForm().
+		AddInputField("Query", "", 0).
+		AddButton("Search")
+	ui.ConfigureBox(form.Box, &cfg.Theme)
+	ui.UpdateBoxFocus(form.Box, &cfg.Theme, tview.FocusMsg{})
+	form.SetTitle("Search messages (from: in: has: before: after:)")
+	return &Prompt{Form: form}
+}
+
+var _ tview.Model = (*Prompt)(nil)
+
+// Reset clears the previous query.
+func (p *Prompt) Reset() {
+	p.GetFormItem(0).(*tview.InputField).SetText("")
+}
+
+func (p *Prompt) Update(msg tview.Msg) tview.Cmd {
+	switch msg.(type) {
+	case tview.FormSubmitMsg:
+		text := p.GetFormItem(0).(*tview.InputField).Text()
+		query, err := ParseQuery(text)
+		if err != nil {
+			slog.Error("failed to parse search query", "err", err, "query", text)
+			return ui.ShowModal(err.Error(), ui.ModalButton{Label: "Close"})
+		}
+		if query.IsEmpty() {
+			return nil
+		}
+		return func() tview.Msg { return QueryMsg{Query: query} }
+	case tview.FormCancelMsg:
+		return func() tview.Msg { return CancelMsg{} }
+	}
+	return p.Form.Update(msg)
+}
@@ -0,0 +1,75 @@
+package searchpicker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// hasValues are the values Discord accepts for the has: filter.
+var hasValues = []string{"link", "embed", "file", "image", "video", "sound", "sticker", "poll", "snapshot"}
+
+// Query is a parsed search query. Names in From and In are resolved against
+// the state by the caller.
+type Query struct {
+	Content string
+	From    []string
+	In      []string
+	Has     []string
+	Before  time.Time
+	After   time.Time
+}
+
+// ParseQuery splits text into free-form content and the from:, in:, has:,
+// before: and after: filters. Dates use the YYYY-MM-DD format.
+func ParseQuery(text string) (Query, error) {
+	var (
+		query   Query
+		content []string
+	)
+	for _, field := range strings.Fields(text) {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok || value == "" {
+			content = append(content, field)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "from":
+			query.From = append(query.From, strings.TrimPrefix(value, "@"))
+		case "in":
+			query.In = append(query.In, strings.TrimPrefix(value, "#"))
+		case "has":
+			value = strings.ToLower(value)
+			if !slices.Contains(hasValues, value) {
+				return Query{}, fmt.Errorf("unknown has: value %q; expected one of %s", value, strings.Join(hasValues, ", "))
+			}
+			query.Has = append(query.Has, value)
+		case "before":
+			date, err := time.ParseInLocation(dateLayout, value, time.Local)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid before: date %q: %w", value, err)
+			}
+			query.Before = date
+		case "after":
+			date, err := time.ParseInLocation(dateLayout, value, time.Local)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid after: date %q: %w", value, err)
+			}
+			query.After = date
+		default:
+			content = append(content, field)
+		}
+	}
+
+	query.Content = strings.Join(content, " ")
+	return query, nil
+}
+
+// IsEmpty reports whether the query has neither content nor filters.
+func (q Query) IsEmpty() bool {
+	return q.Content == "" && len(q.From) == 0 && len(q.In) == 0 && len(q.Has) == 0 && q.Before.IsZero() && q.After.IsZero()
+}
@@ -0,0 +1,42 @@
+package searchpicker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		text string
+		want Query
+	}{
+		{"hello world", Query{Content: "hello world"}},
+		{"from:@alice in:#general deploy", Query{Content: "deploy", From: []string{"alice"}, In: []string{"general"}}},
+		{"has:Link has:image", Query{Has: []string{"link", "image"}}},
+		{"before:2024-01-02 after:2023-12-31", Query{
+			Before: time.Date(2024, time.January, 2, 0, 0, 0, 0, time.Local),
+			After:  time.Date(2023, time.December, 31, 0, 0, 0, 0, time.Local),
+		}},
+		{"http://example.com from:", Query{Content: "http://example.com from:"}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseQuery(test.text)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", test.text, err)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("ParseQuery(%q) mismatch (-want +got):\n%s", test.text, diff)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, text := range []string{"has:nothing", "before:yesterday", "after:2024-13-01"} {
+		if _, err := ParseQuery(text); err == nil {
+			t.Errorf("ParseQuery(%q): expected error", text)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/ningen/v3/discordmd"
+	"github.com/yuin/goldmark/ast"
+)
+
+// spoilerAttachmentPrefix marks the attachments uploaded as spoilers.
+const spoilerAttachmentPrefix = "SPOILER_"
+
+func isSpoilerAttachment(attachment discord.Attachment) bool {
+	return strings.HasPrefix(attachment.Filename, spoilerAttachmentPrefix)
+}
+
+func messageHasSpoilers(message discord.Message) bool {
+	if strings.Contains(message.Content, "||") {
+		return true
+	}
+	for _, attachment := range message.Attachments {
+		if isSpoilerAttachment(attachment) {
+			return true
+		}
+	}
+	return false
+}
+
+// spoilersMasked reports whether the spoilers of the message are drawn as
+// placeholders.
+func (m *Model) spoilersMasked(messageID discord.MessageID) bool {
+	if !m.cfg.Markdown.MaskSpoilers {
+		return false
+	}
+	_, revealed := m.revealedSpoilers[messageID]
+	return !revealed
+}
+
+// toggleSpoilers reveals the spoilers of the selected message, or masks them
+// again. Both lists may show the message, e.g. the message that started a
+// thread.
+func (ml *messagesList) toggleSpoilers() {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok || !messageHasSpoilers(*selectedMessage) {
+		return
+	}
+
+	revealed := ml.chat.revealedSpoilers
+	if _, ok := revealed[selectedMessage.ID]; ok {
+		delete(revealed, selectedMessage.ID)
+	} else {
+		revealed[selectedMessage.ID] = struct{}{}
+	}
+	for _, ml := range []*messagesList{ml.chat.messagesList, ml.chat.threadPane.messagesList} {
+		delete(ml.itemByID, selectedMessage.ID)
+		ml.SetBuilder(ml.buildItem)
+	}
+}
+
+// visibleEmbeds leaves out the embeds of the links hidden behind spoilers
+// while the spoilers of the message are masked. contentRoot is nil when the
+// content was not parsed as markdown.
+func (ml *messagesList) visibleEmbeds(message discord.Message, contentRoot ast.Node, contentSource []byte) []discord.Embed {
+	if len(message.Embeds) == 0 || !ml.chat.spoilersMasked(message.ID) {
+		return message.Embeds
+	}
+
+	if contentRoot == nil {
+		contentRoot, contentSource = parseLinks(message.Content)
+	}
+	urls := spoilerURLs(contentRoot, contentSource)
+	if len(urls) == 0 {
+		return message.Embeds
+	}
+	return slices.DeleteFunc(slices.Clone(message.Embeds), func(embed discord.Embed) bool {
+		return embedHasURL(embed, urls)
+	})
+}
+
+// spoilerURLs returns the links inside spoilers.
+func spoilerURLs(node ast.Node, src []byte) map[string]struct{} {
+	urls := make(map[string]struct{})
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if inline, ok := n.(*discordmd.Inline); ok && entering && inline.Attr&discordmd.AttrSpoiler != 0 {
+			for _, u := range urlsFromAST(inline, src) {
+				urls[u] = struct{}{}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return urls
+}
+
+// embedHasURL reports whether the embed previews one of the urls.
+func embedHasURL(embed discord.Embed, urls map[string]struct{}) bool {
+	embedURLs := []string{embed.URL}
+	if embed.Image != nil {
+		embedURLs = append(embedURLs, embed.Image.URL)
+	}
+	if embed.Thumbnail != nil {
+		embedURLs = append(embedURLs, embed.Thumbnail.URL)
+	}
+	if embed.Video != nil {
+		embedURLs = append(embedURLs, embed.Video.URL)
+	}
+	return slices.ContainsFunc(embedURLs, func(u string) bool {
+		_, ok := urls[u]
+		return ok
+	})
+}
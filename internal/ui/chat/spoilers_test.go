@@ -0,0 +1,34 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+)
+
+func TestVisibleEmbeds(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Markdown.MaskSpoilers = true
+	ml := &messagesList{chat: &Model{cfg: cfg, revealedSpoilers: map[discord.MessageID]struct{}{2: {}}}}
+
+	cat := discord.Embed{URL: "https://example.com/cat"}
+	image := discord.Embed{Thumbnail: &discord.EmbedThumbnail{URL: "https://example.com/cat.png"}}
+	tests := []struct {
+		name    string
+		message discord.Message
+		want    int
+	}{
+		{"no spoilers", discord.Message{ID: 1, Content: "https://example.com/cat", Embeds: []discord.Embed{cat}}, 1},
+		{"spoiler", discord.Message{ID: 1, Content: "||https://example.com/cat||", Embeds: []discord.Embed{cat}}, 0},
+		{"spoiler image", discord.Message{ID: 1, Content: "look ||https://example.com/cat.png||", Embeds: []discord.Embed{cat, image}}, 1},
+		{"other spoiler", discord.Message{ID: 1, Content: "https://example.com/cat ||no link||", Embeds: []discord.Embed{cat}}, 1},
+		{"revealed", discord.Message{ID: 2, Content: "||https://example.com/cat||", Embeds: []discord.Embed{cat}}, 1},
+	}
+
+	for _, test := range tests {
+		if got := ml.visibleEmbeds(test.message, nil, nil); len(got) != test.want {
+			t.Errorf("%s: visibleEmbeds() = %d embeds, want %d", test.name, len(got), test.want)
+		}
+	}
+}
@@ -31,12 +31,15 @@ func (m *Model) onRaw(event *ws.RawEvent) {
 }
 
 func (m *Model) onReady(event *gateway.ReadyEvent) tview.Cmd {
+	m.sessionID = event.SessionID
+
 	// Rebuild indexes from scratch so reconnects and account switches do not
 	// retain pointers to detached tree nodes.
 	m.guildsTree.resetNodeIndex()
 
 	dmNode := tree.NewNode("Direct Messages").SetReference(dmNode{}).SetExpandable(true).SetExpanded(false)
 	m.guildsTree.dmRootNode = dmNode
+	m.guildsTree.setNodeBadge(dmNode, m.guildsTree.dmMentions())
 
 	root := m.guildsTree.
 		Root().
@@ -95,14 +98,38 @@ func (m *Model) onReady(event *gateway.ReadyEvent) tview.Cmd {
 }
 
 func (m *Model) onMessageCreate(message *gateway.MessageCreateEvent) tview.Cmd {
-	selectedChannel, ok := m.SelectedChannel()
-	if ok && selectedChannel.ID == message.ChannelID {
-		m.removeTyper(message.Author.ID)
-		m.messagesList.addMessage(message.Message)
-		return nil
+	// Messages in DMs always count as mentions.
+	if !message.GuildID.IsValid() || m.state.MessageMentions(&message.Message) != 0 {
+		m.guildsTree.updateBadges(message.GuildID, message.ChannelID)
 	}
 
-	return m.notify(*message)
+	lists := m.messagesListsFor(message.ChannelID)
+	if len(lists) == 0 {
+		return m.notify(*message)
+	}
+
+	if selectedChannel, ok := m.SelectedChannel(); ok && selectedChannel.ID == message.ChannelID {
+		m.removeTyper(message.Author.ID)
+	}
+	for _, ml := range lists {
+		// Sending a message reads the channel, so drop the unread separator.
+		// Its row goes away, so the selection is put back on its message.
+		prevCursor := ml.Cursor()
+		if m.isMe(message.Author.ID) {
+			ml.lastReadID = 0
+		}
+		// A detached window does not reach the latest message, so appending
+		// would leave a gap in the history. Buffer until the window catches up.
+		if ml.detached {
+			ml.pending = append(ml.pending, message.Message)
+		} else {
+			ml.addMessage(message.Message)
+		}
+		if prevCursor >= 0 && ml.Cursor() != prevCursor {
+			ml.SetCursor(prevCursor)
+		}
+	}
+	return nil
 }
 
 func (m *Model) notify(message gateway.MessageCreateEvent) tview.Cmd {
@@ -153,58 +180,103 @@ func (m *Model) notify(message gateway.MessageCreateEvent) tview.Cmd {
 
 func (m *Model) onPresenceUpdate(presence *gateway.PresenceUpdateEvent) {
 	m.guildsTree.updateDMNodeStyle(presence.User.ID)
+	m.memberList.onPresenceUpdate(presence)
 }
 
 func (m *Model) onMessageUpdate(message *gateway.MessageUpdateEvent) {
-	selectedChannel, ok := m.SelectedChannel()
-	if !ok || selectedChannel.ID != message.ChannelID {
-		return
+	recorded := false
+	// The same message may be loaded in both lists.
+	recordEdit := func(old discord.Message) {
+		if m.audit != nil && !recorded {
+			m.audit.recordEdit(old, message.Message)
+			recorded = true
+		}
 	}
 
-	index := slices.IndexFunc(m.messagesList.messages, func(m discord.Message) bool {
+	isMessage := func(m discord.Message) bool {
 		return m.ID == message.ID
-	})
-	if index < 0 {
-		return
 	}
+	for _, ml := range m.messagesListsFor(message.ChannelID) {
+		// Messages buffered by a detached window are drawn once it catches up.
+		if index := slices.IndexFunc(ml.pending, isMessage); index >= 0 {
+			recordEdit(ml.pending[index])
+			ml.pending[index] = message.Message
+			continue
+		}
 
-	m.messagesList.setMessage(index, message.Message)
-}
+		index := slices.IndexFunc(ml.messages, isMessage)
+		if index < 0 {
+			continue
+		}
 
-func (m *Model) onMessageDelete(message *gateway.MessageDeleteEvent) {
-	selectedChannel, ok := m.SelectedChannel()
-	if !ok || selectedChannel.ID != message.ChannelID {
-		return
+		recordEdit(ml.messages[index])
+		ml.setMessage(index, message.Message)
 	}
+}
 
-	prevCursor := m.messagesList.Cursor()
-	deletedIndex := slices.IndexFunc(m.messagesList.messages, func(m discord.Message) bool {
-		return m.ID == message.ID
-	})
-	if deletedIndex < 0 {
+func (m *Model) onMessageDelete(channelID discord.ChannelID, messageID discord.MessageID) {
+	// In audit mode, deleted messages stay and are redrawn as deleted.
+	if m.audit != nil {
+		m.audit.markDeleted(messageID)
+		for _, ml := range m.messagesListsFor(channelID) {
+			delete(ml.itemByID, messageID)
+			ml.rebuildRows()
+		}
 		return
 	}
 
-	m.messagesList.deleteMessage(deletedIndex)
+	for _, ml := range m.messagesListsFor(channelID) {
+		ml.pending = slices.DeleteFunc(ml.pending, func(m discord.Message) bool {
+			return m.ID == messageID
+		})
 
-	newCursor := cursorAfterDelete(prevCursor, deletedIndex, len(m.messagesList.messages))
-	if newCursor != prevCursor {
-		m.messagesList.SetCursor(newCursor)
+		prevCursor := ml.Cursor()
+		deletedIndex := slices.IndexFunc(ml.messages, func(m discord.Message) bool {
+			return m.ID == messageID
+		})
+		if deletedIndex < 0 {
+			continue
+		}
+
+		ml.deleteMessage(deletedIndex)
+
+		newCursor := cursorAfterDelete(prevCursor, deletedIndex, len(ml.messages))
+		if newCursor != prevCursor {
+			ml.SetCursor(newCursor)
+		}
 	}
 }
 
 func (m *Model) onMessageReaction(channelID discord.ChannelID, messageID discord.MessageID) {
-	selectedChannel, ok := m.SelectedChannel()
-	if !ok || selectedChannel.ID != channelID {
+	lists := m.messagesListsFor(channelID)
+	if len(lists) == 0 {
 		return
 	}
 
-	index := slices.IndexFunc(m.messagesList.messages, func(message discord.Message) bool {
-		return message.ID == messageID
-	})
 	message, err := m.state.Cabinet.Message(channelID, messageID)
-	if index >= 0 && err == nil {
-		m.messagesList.setMessage(index, *message)
+	if err != nil {
+		return
+	}
+	for _, ml := range lists {
+		index := slices.IndexFunc(ml.messages, func(message discord.Message) bool {
+			return message.ID == messageID
+		})
+		if index >= 0 {
+			ml.setMessage(index, *message)
+		}
+	}
+}
+
+// onThreadUpdate redraws the message a thread was started from so that its
+// reply count and name stay current.
+func (m *Model) onThreadUpdate(thread discord.Channel) {
+	for _, ml := range m.messagesListsFor(thread.ParentID) {
+		index := slices.IndexFunc(ml.messages, func(message discord.Message) bool {
+			return message.ID == discord.MessageID(thread.ID)
+		})
+		if index >= 0 {
+			ml.setMessage(index, ml.messages[index])
+		}
 	}
 }
 
@@ -224,6 +296,9 @@ func cursorAfterDelete(prevCursor, deletedIndex, remaining int) int {
 
 func (m *Model) onGuildMembersChunk(event *gateway.GuildMembersChunkEvent) tview.Cmd {
 	m.messagesList.invalidateRenderedMessages()
+	if m.threadPane.visible {
+		m.threadPane.messagesList.invalidateRenderedMessages()
+	}
 	return m.composer.onGuildMembersChunk(event)
 }
 
@@ -260,8 +335,10 @@ func (m *Model) onReadUpdate(event *read.UpdateEvent) {
 		if err != nil {
 			indication := m.state.ChannelIsUnread(event.ChannelID, ningen.UnreadOpts{IncludeMutedCategories: true})
 			m.guildsTree.setNodeLineStyle(channelNode, m.guildsTree.unreadStyle(indication))
-			return
+		} else {
+			m.guildsTree.setNodeLineStyle(channelNode, m.guildsTree.channelNodeStyle(*channel))
 		}
-		m.guildsTree.setNodeLineStyle(channelNode, m.guildsTree.channelNodeStyle(*channel))
 	}
+
+	m.guildsTree.updateBadges(event.GuildID, event.ChannelID)
 }
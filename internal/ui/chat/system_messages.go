@@ -0,0 +1,152 @@
+package chat
+
+import (
+	"strconv"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/tview"
+	"github.com/gdamore/tcell/v3"
+)
+
+// drawSystemMessage renders a message Discord generated for an event as a
+// single line of text, prefixed with the author when the event has one.
+func (ml *messagesList) drawSystemMessage(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
+	text, withAuthor, style := ml.systemMessageText(message)
+	if ml.cfg.Timestamps.Enabled {
+		ml.drawTimestamps(builder, message.Timestamp, baseStyle)
+	}
+	if withAuthor {
+		ml.drawAuthor(builder, message, baseStyle)
+	}
+	builder.Write(text, tview.MergeStyle(baseStyle, style.Style))
+
+	// AutoMod alerts carry the blocked content and the rule in an embed.
+	if message.Type == discord.AutoModerationActionMessage {
+		ml.drawEmbeds(builder, message, baseStyle, nil, nil)
+	}
+}
+
+// systemMessageText returns the text of a system message, whether it reads
+// as a sentence about the author, and the theme style it is drawn with.
+func (ml *messagesList) systemMessageText(message discord.Message) (string, bool, config.StyleWrapper) {
+	theme := ml.cfg.Theme.MessagesList.System
+	switch message.Type {
+	case discord.GuildMemberJoinMessage:
+		return "joined the server.", true, theme.JoinStyle
+	case discord.RecipientAddMessage:
+		return "added " + mentionedUser(message) + " to the group.", true, theme.RecipientStyle
+	case discord.RecipientRemoveMessage:
+		if len(message.Mentions) == 0 || message.Mentions[0].ID == message.Author.ID {
+			return "left the group.", true, theme.RecipientStyle
+		}
+		return "removed " + mentionedUser(message) + " from the group.", true, theme.RecipientStyle
+	case discord.CallMessage:
+		return "started a call.", true, theme.CallStyle
+	case discord.ChannelNameChangeMessage:
+		return "changed the channel name: " + message.Content, true, theme.ChannelStyle
+	case discord.ChannelIconChangeMessage:
+		return "changed the channel icon.", true, theme.ChannelStyle
+	case discord.ChannelPinnedMessage:
+		return "pinned a message to this channel.", true, theme.PinStyle
+	case discord.NitroBoostMessage:
+		// The content holds the number of boosts when there is more than one.
+		if n, err := strconv.Atoi(message.Content); err == nil && n > 1 {
+			return "just boosted the server " + message.Content + " times!", true, theme.BoostStyle
+		}
+		return "just boosted the server!", true, theme.BoostStyle
+	case discord.NitroTier1Message, discord.NitroTier2Message, discord.NitroTier3Message:
+		level := strconv.Itoa(int(message.Type - discord.NitroBoostMessage))
+		return "just boosted the server! " + ml.guildName(message.GuildID) + " has achieved Level " + level + "!", true, theme.BoostStyle
+	case discord.ChannelFollowAddMessage:
+		return "has added " + message.Content + " to this channel. Its most important updates will show up here.", true, theme.FollowStyle
+	case discord.GuildDiscoveryDisqualifiedMessage:
+		return "This server has been removed from Server Discovery because it no longer passes all the requirements.", false, theme.DiscoveryStyle
+	case discord.GuildDiscoveryRequalifiedMessage:
+		return "This server is eligible for Server Discovery again and has been automatically relisted!", false, theme.DiscoveryStyle
+	case discord.GuildDiscoveryGracePeriodInitialWarning:
+		return "This server has failed Discovery activity requirements for 1 week.", false, theme.DiscoveryStyle
+	case discord.GuildDiscoveryGracePeriodFinalWarning:
+		return "This server has failed Discovery activity requirements for 3 weeks in a row.", false, theme.DiscoveryStyle
+	case discord.GuildInviteReminderMessage:
+		return "Wondering who to invite? Start by inviting anyone who can help you build the server!", false, theme.DiscoveryStyle
+	case discord.ThreadCreatedMessage:
+		return "started a thread: " + message.Content, true, theme.ThreadStyle
+	case discord.AutoModerationActionMessage:
+		return "AutoMod has blocked a message from " + message.Author.DisplayOrUsername() + ".", false, theme.AutoModStyle
+	case discord.RoleSubscriptionPurchaseMessage:
+		return "joined as a role subscriber.", true, theme.SubscriptionStyle
+	case discord.GuildApplicationPremiumSubscriptionMessage:
+		return "upgraded an app to premium for this server.", true, theme.SubscriptionStyle
+	case discord.PurchaseNotificationMessage:
+		return "made a purchase in this server.", true, theme.SubscriptionStyle
+	case discord.StageStartMessage:
+		return "started " + message.Content + ".", true, theme.StageStyle
+	case discord.StageEndMessage:
+		return "ended " + message.Content + ".", true, theme.StageStyle
+	case discord.StageSpeakerMessage:
+		return "is now a speaker.", true, theme.StageStyle
+	case discord.StageRaiseHandMessage:
+		return "requested to speak.", true, theme.StageStyle
+	case discord.StageTopicMessage:
+		return "changed the Stage topic: " + message.Content, true, theme.StageStyle
+	case discord.GuildIncidentAlertModeEnabledMessage:
+		return "enabled security actions.", true, theme.IncidentStyle
+	case discord.GuildIncidentAlertModeDisabledMessage:
+		return "disabled security actions.", true, theme.IncidentStyle
+	case discord.GuildIncidentReportRaidMessage:
+		return "reported a raid in " + ml.guildName(message.GuildID) + ".", true, theme.IncidentStyle
+	case discord.GuildIncidentReportFalseAlarmMessage:
+		return "reported a false alarm in " + ml.guildName(message.GuildID) + ".", true, theme.IncidentStyle
+	case discord.PollResultMessage:
+		text := "'s poll has closed."
+		if question := embedField(message, "poll_question_text"); question != "" {
+			text = "'s poll " + question + " has closed."
+		}
+		if winner := embedField(message, "victor_answer_text"); winner != "" {
+			text += " Winning answer: " + winner
+		}
+		return message.Author.DisplayOrUsername() + text, false, theme.PollResultStyle
+	default:
+		return "sent a message of an unsupported type (" + strconv.Itoa(int(message.Type)) + ").", true, theme.UnknownStyle
+	}
+}
+
+// drawThreadStarterMessage renders the first message of a thread, which
+// points at the message the thread was started from.
+func (ml *messagesList) drawThreadStarterMessage(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
+	if m := message.ReferencedMessage; m != nil {
+		m.GuildID = message.GuildID
+		ml.drawDefaultMessage(builder, *m, baseStyle)
+		return
+	}
+	builder.Write("Original message was deleted", baseStyle.Dim(true))
+}
+
+func mentionedUser(message discord.Message) string {
+	if len(message.Mentions) == 0 {
+		return "someone"
+	}
+	return message.Mentions[0].DisplayOrUsername()
+}
+
+func (ml *messagesList) guildName(guildID discord.GuildID) string {
+	if guild, err := ml.chat.state.Cabinet.Guild(guildID); err == nil {
+		return guild.Name
+	}
+	return "the server"
+}
+
+// embedField returns the value of the named field of the message's first
+// embed. Discord uses these to carry the details of some system messages.
+func embedField(message discord.Message, name string) string {
+	if len(message.Embeds) == 0 {
+		return ""
+	}
+	for _, field := range message.Embeds[0].Fields {
+		if field.Name == name {
+			return field.Value
+		}
+	}
+	return ""
+}
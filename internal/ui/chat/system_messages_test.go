@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+)
+
+func TestSystemMessageText(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ml := &messagesList{cfg: cfg}
+
+	alice := discord.User{ID: 1, Username: "alice"}
+	bob := discord.GuildUser{User: discord.User{ID: 2, Username: "bob"}}
+	tests := []struct {
+		message        discord.Message
+		wantText       string
+		wantWithAuthor bool
+	}{
+		{discord.Message{Type: discord.GuildMemberJoinMessage}, "joined the server.", true},
+		{discord.Message{Type: discord.RecipientAddMessage, Mentions: []discord.GuildUser{bob}}, "added bob to the group.", true},
+		{discord.Message{Type: discord.RecipientAddMessage}, "added someone to the group.", true},
+		{discord.Message{Type: discord.RecipientRemoveMessage, Author: alice, Mentions: []discord.GuildUser{bob}}, "removed bob from the group.", true},
+		{discord.Message{Type: discord.RecipientRemoveMessage, Author: alice, Mentions: []discord.GuildUser{{User: alice}}}, "left the group.", true},
+		{discord.Message{Type: discord.ChannelNameChangeMessage, Content: "general"}, "changed the channel name: general", true},
+		{discord.Message{Type: discord.ChannelPinnedMessage}, "pinned a message to this channel.", true},
+		{discord.Message{Type: discord.NitroBoostMessage}, "just boosted the server!", true},
+		{discord.Message{Type: discord.NitroBoostMessage, Content: "3"}, "just boosted the server 3 times!", true},
+		{discord.Message{Type: discord.ThreadCreatedMessage, Content: "ideas"}, "started a thread: ideas", true},
+		{discord.Message{Type: discord.GuildDiscoveryRequalifiedMessage}, "This server is eligible for Server Discovery again and has been automatically relisted!", false},
+		{discord.Message{Type: discord.AutoModerationActionMessage, Author: alice}, "AutoMod has blocked a message from alice.", false},
+		{discord.Message{Type: discord.StageTopicMessage, Content: "Q&A"}, "changed the Stage topic: Q&A", true},
+		{discord.Message{Type: discord.PollResultMessage, Author: alice}, "alice's poll has closed.", false},
+		{
+			discord.Message{Type: discord.PollResultMessage, Author: alice, Embeds: []discord.Embed{{Fields: []discord.EmbedField{
+				{Name: "poll_question_text", Value: "Tabs?"},
+				{Name: "victor_answer_text", Value: "Yes"},
+			}}}},
+			"alice's poll Tabs? has closed. Winning answer: Yes",
+			false,
+		},
+		{discord.Message{Type: 255}, "sent a message of an unsupported type (255).", true},
+	}
+
+	for _, test := range tests {
+		text, withAuthor, _ := ml.systemMessageText(test.message)
+		if text != test.wantText || withAuthor != test.wantWithAuthor {
+			t.Errorf("systemMessageText(type %d) = %q, %v, want %q, %v", test.message.Type, text, withAuthor, test.wantText, test.wantWithAuthor)
+		}
+	}
+}
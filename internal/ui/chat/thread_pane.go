@@ -0,0 +1,212 @@
+package chat
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/config"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/flex"
+	"github.com/gdamore/tcell/v3"
+)
+
+// threadPane shows a thread next to its parent channel, with its own
+// messages list and composer.
+type threadPane struct {
+	*flex.Model
+	messagesList *messagesList
+	composer     *composer
+	visible      bool
+}
+
+func newThreadPane(cfg *config.Config, chat *Model) *threadPane {
+	tp := &threadPane{
+		Model:        flex.NewModel(),
+		messagesList: newMessagesList(cfg, chat),
+		composer:     newComposer(cfg, chat),
+	}
+	// Only one composer is focused at a time, so the mentions list layer is
+	// shared with the main composer.
+	tp.composer.mentionsList = chat.composer.mentionsList
+	bindComposer(tp.messagesList, tp.composer, tp.Model)
+
+	tp.
+		SetDirection(flex.DirectionRow).
+		AddItem(tp.messagesList, 0, 1, false).
+		AddItem(tp.composer, 3, 1, false)
+	return tp
+}
+
+// bindComposer pairs a messages list with the composer that replies to,
+// edits and sends messages in its channel. parent is the flex holding both.
+func bindComposer(ml *messagesList, c *composer, parent *flex.Model) {
+	ml.composer = c
+	c.messagesList = ml
+	c.parent = parent
+}
+
+func (tp *threadPane) thread() (*discord.Channel, bool) {
+	return tp.messagesList.thread, tp.visible && tp.messagesList.thread != nil
+}
+
+// threadOf returns the thread started from the message, if any. Threads
+// started from a message share its ID; the state holds the up-to-date reply
+// count for active threads.
+func (ml *messagesList) threadOf(message discord.Message) *discord.Channel {
+	channel, err := ml.chat.state.Cabinet.Channel(discord.ChannelID(message.ID))
+	if err == nil && isThread(channel.Type) {
+		return channel
+	}
+	return message.Thread
+}
+
+func (ml *messagesList) drawThread(builder *tview.LineBuilder, message discord.Message, baseStyle tcell.Style) {
+	thread := ml.threadOf(message)
+	if thread == nil {
+		return
+	}
+
+	replies := strconv.Itoa(thread.MessageCount) + " replies"
+	if thread.MessageCount == 1 {
+		replies = "1 reply"
+	}
+
+	builder.NewLine()
+	style := tview.MergeStyle(baseStyle, ml.cfg.Theme.MessagesList.ThreadStyle.Style)
+	builder.Write(replies+" · "+thread.Name, style)
+}
+
+// toggleThread opens the thread of the selected message in the thread pane,
+// or closes the pane when used from it.
+func (ml *messagesList) toggleThread() tview.Cmd {
+	if ml.thread != nil {
+		return ml.chat.closeThread()
+	}
+
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok {
+		return nil
+	}
+
+	thread := ml.threadOf(*selectedMessage)
+	if thread == nil {
+		return nil
+	}
+	return ml.chat.openThread(*thread)
+}
+
+func (m *Model) openThread(thread discord.Channel) tview.Cmd {
+	limit := uint(m.cfg.MessagesLimit)
+	return func() tview.Msg {
+		messages, err := m.state.Messages(thread.ID, limit)
+		if err != nil {
+			slog.Error("failed to get thread messages", "err", err, "channel_id", thread.ID, "limit", limit)
+			return nil
+		}
+		return threadLoadedMsg{Thread: thread, Messages: messages}
+	}
+}
+
+// openThreadAround opens the thread at a window of history around the
+// message.
+func (m *Model) openThreadAround(thread discord.Channel, messageID discord.MessageID) tview.Cmd {
+	limit := uint(m.cfg.MessagesLimit)
+	return func() tview.Msg {
+		messages, err := m.state.MessagesAround(thread.ID, messageID, limit)
+		if err != nil {
+			slog.Error("failed to get thread messages around message", "err", err, "channel_id", thread.ID, "message_id", messageID, "limit", limit)
+			return nil
+		}
+
+		// Messages are returned newest first.
+		detached := len(messages) > 0 && messages[0].ID < m.state.LastMessage(thread.ID)
+		return threadLoadedMsg{Thread: thread, Messages: messages, TargetID: messageID, Detached: detached}
+	}
+}
+
+func (m *Model) onThreadLoaded(msg threadLoadedMsg) tview.Cmd {
+	tp := m.threadPane
+	ml := tp.messagesList
+	ml.reset()
+	ml.thread = &msg.Thread
+	ml.setTitle(msg.Thread)
+	ml.detached = msg.Detached
+	ml.setMessages(msg.Messages)
+	if msg.TargetID.IsValid() {
+		ml.highlightMessage(msg.TargetID)
+	} else {
+		ml.ScrollBottom()
+	}
+
+	text := "Reply in thread..."
+	disabled := false
+	switch {
+	case msg.Thread.ThreadMetadata != nil && msg.Thread.ThreadMetadata.Archived:
+		text = "This thread is archived."
+		disabled = true
+	case !m.state.HasPermissions(msg.Thread.ParentID, discord.PermissionSendMessagesInThreads):
+		text = "You do not have permission to send messages in this thread."
+		disabled = true
+	}
+	tp.composer.reset()
+	tp.composer.SetDisabled(disabled)
+	tp.composer.SetPlaceholder(tview.NewLine(tview.NewSegment(text, tcell.StyleDefault.Dim(true))))
+
+	if !tp.visible {
+		tp.visible = true
+		m.mainFlex.AddItem(tp, 0, threadPaneProportion(m.cfg), false)
+		// Keep the member list on the right edge.
+		if m.memberList.visible {
+			m.mainFlex.RemoveItem(m.memberList)
+			m.mainFlex.AddItem(m.memberList, 0, m.cfg.MemberList.WidthPercent, false)
+		}
+	}
+
+	focus := tview.SetFocus(ml)
+	if msg.Thread.GuildID.IsValid() {
+		return tview.Batch(focus, ml.requestGuildMembers(msg.Thread.GuildID, msg.Messages))
+	}
+	return focus
+}
+
+func (m *Model) closeThread() tview.Cmd {
+	tp := m.threadPane
+	if !tp.visible {
+		return nil
+	}
+
+	tp.visible = false
+	m.mainFlex.RemoveItem(tp)
+	tp.messagesList.reset()
+	tp.messagesList.thread = nil
+	tp.composer.reset()
+	return tview.SetFocus(m.messagesList)
+}
+
+// threadPaneProportion sizes the thread pane to half of the messages pane.
+func threadPaneProportion(cfg *config.Config) int {
+	return (100 - cfg.Sidebar.WidthPercent) / 2
+}
+
+// messagesListsFor returns the lists showing the channel: the main list, the
+// thread pane, or both when the thread is also selected in the tree.
+func (m *Model) messagesListsFor(channelID discord.ChannelID) []*messagesList {
+	var lists []*messagesList
+	if selectedChannel, ok := m.SelectedChannel(); ok && selectedChannel.ID == channelID {
+		lists = append(lists, m.messagesList)
+	}
+	if thread, ok := m.threadPane.thread(); ok && thread.ID == channelID {
+		lists = append(lists, m.threadPane.messagesList)
+	}
+	return lists
+}
+
+// activeMessagesList returns the list the user is working in; pickers opened
+// from a list report back to it.
+func (m *Model) activeMessagesList() *messagesList {
+	if m.threadPane.visible && (m.focused == m.threadPane.messagesList || m.focused == m.threadPane.composer) {
+		return m.threadPane.messagesList
+	}
+	return m.messagesList
+}
@@ -0,0 +1,137 @@
+package chat
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/ayn2op/arikawa/v3/api"
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/arikawa/v3/utils/json/option"
+	"github.com/ayn2op/tview"
+)
+
+// currentThread returns the thread the thread actions apply to: the thread
+// started from the selected message, or the list's channel if it is a thread.
+func (ml *messagesList) currentThread() (*discord.Channel, bool) {
+	if selectedMessage, ok := ml.selectedMessage(); ok {
+		if thread := ml.threadOf(*selectedMessage); thread != nil {
+			return thread, true
+		}
+	}
+
+	if channel, ok := ml.selectedChannel(); ok && isThread(channel.Type) {
+		return channel, true
+	}
+	return nil, false
+}
+
+// canStartThread reports whether threads can be started in the list's
+// channel.
+func (ml *messagesList) canStartThread() bool {
+	channel, ok := ml.selectedChannel()
+	if !ok || !channel.GuildID.IsValid() || isThread(channel.Type) || channel.Type == discord.GuildForum {
+		return false
+	}
+	return ml.chat.state.HasPermissions(channel.ID, discord.PermissionCreatePublicThreads)
+}
+
+// canArchiveThread reports whether the user may archive or unarchive the
+// thread. Thread permissions are inherited from the parent channel.
+func (ml *messagesList) canArchiveThread(thread discord.Channel) bool {
+	return ml.chat.isMe(thread.OwnerID) || ml.chat.state.HasPermissions(thread.ParentID, discord.PermissionManageThreads)
+}
+
+// startThread prompts for a name and starts a thread from the selected
+// message, or a new thread in the channel when no message is selected.
+func (ml *messagesList) startThread() tview.Cmd {
+	if !ml.canStartThread() {
+		return nil
+	}
+
+	selectedChannel, _ := ml.selectedChannel()
+	channel := *selectedChannel
+
+	var message *discord.Message
+	title := "Start thread in " + channel.Name
+	if selectedMessage, ok := ml.selectedMessage(); ok {
+		if ml.threadOf(*selectedMessage) != nil {
+			return nil
+		}
+		message = selectedMessage
+		title = "Start thread from message"
+	}
+
+	return ml.chat.openPrompt(title, "Name", func(name string) tview.Cmd {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil
+		}
+
+		return func() tview.Msg {
+			data := api.StartThreadData{Name: name}
+
+			var (
+				thread *discord.Channel
+				err    error
+			)
+			if message != nil {
+				thread, err = ml.chat.state.StartThreadWithMessage(channel.ID, message.ID, data)
+			} else {
+				data.Type = discord.GuildPublicThread
+				thread, err = ml.chat.state.StartThreadWithoutMessage(channel.ID, data)
+			}
+			if err != nil {
+				slog.Error("failed to start thread", "err", err, "channel_id", channel.ID)
+				return nil
+			}
+			return ml.chat.openThread(*thread)()
+		}
+	})
+}
+
+func (ml *messagesList) toggleThreadMembership() tview.Cmd {
+	thread, ok := ml.currentThread()
+	if !ok {
+		return nil
+	}
+
+	threadID := thread.ID
+	joined := thread.SelfThreadMember != nil
+	return func() tview.Msg {
+		if joined {
+			if err := ml.chat.state.LeaveThread(threadID); err != nil {
+				slog.Error("failed to leave thread", "err", err, "channel_id", threadID)
+			}
+			return nil
+		}
+
+		if err := ml.chat.state.JoinThread(threadID); err != nil {
+			slog.Error("failed to join thread", "err", err, "channel_id", threadID)
+		}
+		return nil
+	}
+}
+
+func (ml *messagesList) toggleThreadArchived() tview.Cmd {
+	thread, ok := ml.currentThread()
+	if !ok {
+		return nil
+	}
+
+	if !ml.canArchiveThread(*thread) {
+		slog.Error("failed to toggle thread archive; missing relevant permissions", "channel_id", thread.ID)
+		return nil
+	}
+
+	threadID := thread.ID
+	archived := option.True
+	if thread.ThreadMetadata != nil && thread.ThreadMetadata.Archived {
+		archived = option.False
+	}
+	return func() tview.Msg {
+		if err := ml.chat.state.ModifyChannel(threadID, api.ModifyChannelData{Archived: archived}); err != nil {
+			slog.Error("failed to modify thread", "err", err, "channel_id", threadID)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/markdown"
+	"github.com/ayn2op/tview"
+)
+
+// relativeTimestampInterval is how often relative timestamps, such as
+// "in 5 minutes", are brought up to date.
+const relativeTimestampInterval = 30 * time.Second
+
+type relativeTimestampTickMsg struct{}
+
+func tickRelativeTimestamps() tview.Cmd {
+	return func() tview.Msg {
+		time.Sleep(relativeTimestampInterval)
+		return relativeTimestampTickMsg{}
+	}
+}
+
+// onRelativeTimestampTick re-renders the messages with relative timestamps;
+// the other messages keep their rendered items.
+func (m *Model) onRelativeTimestampTick() tview.Cmd {
+	for _, ml := range []*messagesList{m.messagesList, m.threadPane.messagesList} {
+		changed := false
+		for _, message := range ml.messages {
+			if hasRelativeTimestamp(message) {
+				delete(ml.itemByID, message.ID)
+				changed = true
+			}
+		}
+		if changed {
+			ml.SetBuilder(ml.buildItem)
+		}
+	}
+	return tickRelativeTimestamps()
+}
+
+func hasRelativeTimestamp(message discord.Message) bool {
+	if markdown.HasRelativeTimestamp(message.Content) {
+		return true
+	}
+	for _, embed := range message.Embeds {
+		if markdown.HasRelativeTimestamp(embed.Description) {
+			return true
+		}
+		for _, field := range embed.Fields {
+			if markdown.HasRelativeTimestamp(field.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package chat
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/tree"
+)
+
+// channelMentions returns the number of unread mentions in the channel. Every
+// unread message of a DM counts as a mention; muted channels have none.
+func (gt *guildsTree) channelMentions(channelID discord.ChannelID) int {
+	if gt.channelMuted(channelID) {
+		return 0
+	}
+	if readState := gt.state.ReadState.ReadState(channelID); readState != nil {
+		return readState.MentionCount
+	}
+	return 0
+}
+
+func (gt *guildsTree) guildMentions(guildID discord.GuildID) int {
+	if gt.guildMuted(guildID) {
+		return 0
+	}
+
+	channels, err := gt.state.Cabinet.Channels(guildID)
+	if err != nil {
+		slog.Error("failed to get channels from state", "err", err, "guild_id", guildID)
+		return 0
+	}
+
+	var mentions int
+	for _, channel := range channels {
+		mentions += gt.channelMentions(channel.ID)
+	}
+	return mentions
+}
+
+func (gt *guildsTree) dmMentions() int {
+	channels, err := gt.state.PrivateChannels()
+	if err != nil {
+		slog.Error("failed to get private channels", "err", err)
+		return 0
+	}
+
+	var mentions int
+	for _, channel := range channels {
+		mentions += gt.channelMentions(channel.ID)
+	}
+	return mentions
+}
+
+// folderMentions adds up the mentions of the guilds in the folder.
+func (gt *guildsTree) folderMentions(folderNode *tree.Node) int {
+	var mentions int
+	for _, guildNode := range folderNode.Children() {
+		if guildID, ok := guildNode.Reference().(discord.GuildID); ok {
+			mentions += gt.guildMentions(guildID)
+		}
+	}
+	return mentions
+}
+
+// setNodeBadge draws the number of mentions after the label of the node, or
+// removes the badge when there are none.
+func (gt *guildsTree) setNodeBadge(node *tree.Node, mentions int) {
+	if !gt.cfg.Sidebar.UnreadIndicator.Badges() {
+		return
+	}
+
+	line := slices.Clone(node.Line()[:gt.labelLen(node)])
+	delete(gt.badged, node)
+	if mentions > 0 {
+		badge := tview.NewSegment(" ("+strconv.Itoa(mentions)+")", gt.cfg.Theme.GuildsTree.MentionBadgeStyle.Style)
+		line = append(line, badge)
+		gt.badged[node] = struct{}{}
+	}
+	node.SetLine(line)
+}
+
+// labelLen returns the number of segments of the line of the node before its
+// badge.
+func (gt *guildsTree) labelLen(node *tree.Node) int {
+	n := len(node.Line())
+	if _, ok := gt.badged[node]; ok && n > 0 {
+		n--
+	}
+	return n
+}
+
+// updateBadges brings the badges of the channel and of the guild, folder or
+// DMs holding it up to date.
+func (gt *guildsTree) updateBadges(guildID discord.GuildID, channelID discord.ChannelID) {
+	if !gt.cfg.Sidebar.UnreadIndicator.Badges() {
+		return
+	}
+
+	if channelNode := gt.channelNodeByID[channelID]; channelNode != nil {
+		gt.setNodeBadge(channelNode, gt.channelMentions(channelID))
+	}
+
+	if !guildID.IsValid() {
+		if gt.dmRootNode != nil {
+			gt.setNodeBadge(gt.dmRootNode, gt.dmMentions())
+		}
+		return
+	}
+	if guildNode := gt.guildNodeByID[guildID]; guildNode != nil {
+		gt.setNodeBadge(guildNode, gt.guildMentions(guildID))
+	}
+	if folderNode := gt.folderNodeByGuildID[guildID]; folderNode != nil {
+		gt.setNodeBadge(folderNode, gt.folderMentions(folderNode))
+	}
+}
@@ -0,0 +1,131 @@
+package chat
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/ningen/v3"
+	"github.com/ayn2op/tview"
+	"github.com/ayn2op/tview/tree"
+)
+
+// goToNextUnread opens the next unread channel after the selected one, or the
+// next channel that mentions the user when mentionsOnly is set.
+func (m *Model) goToNextUnread(mentionsOnly bool) tview.Cmd {
+	var after discord.ChannelID
+	if selected, ok := m.SelectedChannel(); ok {
+		after = selected.ID
+	}
+
+	channelID, ok := m.guildsTree.nextUnreadChannel(after, mentionsOnly)
+	if !ok {
+		return nil
+	}
+	return m.navigateToChannel(channelID)
+}
+
+// nextUnreadChannel walks the channels in the order of the tree, starting after
+// the given channel and wrapping around, and returns the first one that is
+// unread or, when mentionsOnly is set, mentions the user.
+func (gt *guildsTree) nextUnreadChannel(after discord.ChannelID, mentionsOnly bool) (discord.ChannelID, bool) {
+	channelIDs := gt.navigableChannels()
+	start := slices.Index(channelIDs, after) + 1
+	for i := range channelIDs {
+		channelID := channelIDs[(start+i)%len(channelIDs)]
+		if channelID == after {
+			continue
+		}
+
+		// Without IncludeMutedCategories, muted channels and categories are
+		// reported as read.
+		switch gt.state.ChannelIsUnread(channelID, ningen.UnreadOpts{}) {
+		case ningen.ChannelMentioned:
+			return channelID, true
+		case ningen.ChannelUnread:
+			if !mentionsOnly {
+				return channelID, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// navigableChannels returns the channels that hold messages in the order they
+// appear in the tree: direct messages first, then the guilds in their
+// GuildPositions and folder order.
+func (gt *guildsTree) navigableChannels() []discord.ChannelID {
+	var channelIDs []discord.ChannelID
+	for _, node := range gt.Root().Children() {
+		switch ref := node.Reference().(type) {
+		case dmNode:
+			channelIDs = gt.appendPrivateChannels(channelIDs)
+		case discord.GuildID:
+			channelIDs = gt.appendGuildChannels(channelIDs, ref)
+		default: // Folder
+			channelIDs = gt.appendFolderChannels(channelIDs, node)
+		}
+	}
+	return channelIDs
+}
+
+func (gt *guildsTree) appendPrivateChannels(channelIDs []discord.ChannelID) []discord.ChannelID {
+	channels, err := gt.state.PrivateChannels()
+	if err != nil {
+		slog.Error("failed to get private channels", "err", err)
+		return channelIDs
+	}
+
+	ui.SortPrivateChannels(channels)
+	for _, channel := range channels {
+		channelIDs = append(channelIDs, channel.ID)
+	}
+	return channelIDs
+}
+
+func (gt *guildsTree) appendFolderChannels(channelIDs []discord.ChannelID, folderNode *tree.Node) []discord.ChannelID {
+	for _, guildNode := range folderNode.Children() {
+		if guildID, ok := guildNode.Reference().(discord.GuildID); ok {
+			channelIDs = gt.appendGuildChannels(channelIDs, guildID)
+		}
+	}
+	return channelIDs
+}
+
+// appendGuildChannels appends the channels of the guild the way
+// createChannelNodes lays them out: channels outside categories first, then the
+// channels of every category.
+func (gt *guildsTree) appendGuildChannels(channelIDs []discord.ChannelID, guildID discord.GuildID) []discord.ChannelID {
+	if gt.state.MutedState.Guild(guildID, false) {
+		return channelIDs
+	}
+
+	channels, err := gt.state.Cabinet.Channels(guildID)
+	if err != nil {
+		slog.Error("failed to get channels from state", "err", err, "guild_id", guildID)
+		return channelIDs
+	}
+	ui.SortGuildChannels(channels)
+
+	navigable := func(channel discord.Channel) bool {
+		return channel.Type != discord.GuildCategory && channel.Type != discord.GuildForum && !isThread(channel.Type) &&
+			gt.state.HasPermissions(channel.ID, discord.PermissionViewChannel)
+	}
+	for _, channel := range channels {
+		if !channel.ParentID.IsValid() && navigable(channel) {
+			channelIDs = append(channelIDs, channel.ID)
+		}
+	}
+	for _, category := range channels {
+		if category.Type != discord.GuildCategory {
+			continue
+		}
+		for _, channel := range channels {
+			if channel.ParentID == category.ID && navigable(channel) {
+				channelIDs = append(channelIDs, channel.ID)
+			}
+		}
+	}
+	return channelIDs
+}
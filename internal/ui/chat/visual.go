@@ -0,0 +1,279 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayn2op/arikawa/v3/discord"
+	"github.com/ayn2op/discordo/internal/ui"
+	"github.com/ayn2op/tview"
+	"github.com/gdamore/tcell/v3"
+	"golang.design/x/clipboard"
+)
+
+const (
+	transcriptTimeFormat = "2006-01-02 15:04"
+	// bulkDeleteMaxAge is how old messages may be for Discord to delete
+	// them in bulk.
+	bulkDeleteMaxAge = 14 * 24 * time.Hour
+)
+
+// hasMarks reports whether messages are marked, one by one or with a visual
+// range.
+func (ml *messagesList) hasMarks() bool {
+	return len(ml.marked) != 0 || ml.visualAnchorID.IsValid()
+}
+
+// visualRange returns the indexes of the messages spanned by visual mode.
+func (ml *messagesList) visualRange() (int, int, bool) {
+	if !ml.visualAnchorID.IsValid() {
+		return 0, 0, false
+	}
+
+	anchor := ml.messageIndex(ml.visualAnchorID)
+	end := ml.messageIndex(ml.visualEndID)
+	if anchor < 0 || end < 0 {
+		return 0, 0, false
+	}
+	return min(anchor, end), max(anchor, end), true
+}
+
+func (ml *messagesList) messageIndex(messageID discord.MessageID) int {
+	return slices.IndexFunc(ml.messages, func(message discord.Message) bool {
+		return message.ID == messageID
+	})
+}
+
+func (ml *messagesList) isMarked(index int) bool {
+	if _, ok := ml.marked[ml.messages[index].ID]; ok {
+		return true
+	}
+	lo, hi, ok := ml.visualRange()
+	return ok && index >= lo && index <= hi
+}
+
+// markedMessages returns the marked messages from oldest to newest.
+func (ml *messagesList) markedMessages() []discord.Message {
+	var messages []discord.Message
+	for index, message := range ml.messages {
+		if ml.isMarked(index) {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// selectedMessages returns the marked messages, or the selected message when
+// none are marked.
+func (ml *messagesList) selectedMessages() []discord.Message {
+	if ml.hasMarks() {
+		return ml.markedMessages()
+	}
+	if selectedMessage, ok := ml.selectedMessage(); ok {
+		return []discord.Message{*selectedMessage}
+	}
+	return nil
+}
+
+// invalidateMarked redraws the marked messages with or without the marked
+// style.
+func (ml *messagesList) invalidateMarked() {
+	for _, message := range ml.markedMessages() {
+		delete(ml.itemByID, message.ID)
+	}
+	ml.SetBuilder(ml.buildItem)
+}
+
+// toggleVisual starts a visual range on the selected message. Leaving visual
+// mode keeps the range marked, so several ranges can be combined.
+func (ml *messagesList) toggleVisual() {
+	if ml.visualAnchorID.IsValid() {
+		if lo, hi, ok := ml.visualRange(); ok {
+			for _, message := range ml.messages[lo : hi+1] {
+				ml.marked[message.ID] = struct{}{}
+			}
+		}
+		ml.visualAnchorID = 0
+		ml.visualEndID = 0
+		return
+	}
+
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok {
+		return
+	}
+	ml.visualAnchorID = selectedMessage.ID
+	ml.visualEndID = selectedMessage.ID
+	delete(ml.itemByID, selectedMessage.ID)
+	ml.SetBuilder(ml.buildItem)
+}
+
+func (ml *messagesList) toggleMark() {
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok {
+		return
+	}
+
+	if _, ok := ml.marked[selectedMessage.ID]; ok {
+		delete(ml.marked, selectedMessage.ID)
+	} else {
+		ml.marked[selectedMessage.ID] = struct{}{}
+	}
+	delete(ml.itemByID, selectedMessage.ID)
+	ml.SetBuilder(ml.buildItem)
+}
+
+func (ml *messagesList) clearMarks() {
+	ml.invalidateMarked()
+	clear(ml.marked)
+	ml.visualAnchorID = 0
+	ml.visualEndID = 0
+}
+
+// extendVisual moves the end of the visual range to the selected message.
+func (ml *messagesList) extendVisual() {
+	if !ml.visualAnchorID.IsValid() {
+		return
+	}
+
+	selectedMessage, ok := ml.selectedMessage()
+	if !ok || selectedMessage.ID == ml.visualEndID {
+		return
+	}
+	ml.invalidateMarked()
+	ml.visualEndID = selectedMessage.ID
+	ml.invalidateMarked()
+}
+
+// transcript formats the messages as plain text, one "[time] author: content"
+// entry per message followed by its attachments.
+func (ml *messagesList) transcript(messages []discord.Message) string {
+	var b strings.Builder
+	for _, message := range messages {
+		name, _ := ml.authorName(message, tcell.StyleDefault)
+		timestamp := message.Timestamp.Time().In(time.Local).Format(transcriptTimeFormat)
+		content := strings.ReplaceAll(message.Content, "\n", "\n  ")
+		fmt.Fprintf(&b, "[%s] %s: %s\n", timestamp, name, content)
+		for _, attachment := range message.Attachments {
+			fmt.Fprintf(&b, "  %s\n", attachment.URL)
+		}
+	}
+	return b.String()
+}
+
+func (ml *messagesList) yankTranscript() tview.Cmd {
+	transcript := ml.transcript(ml.markedMessages())
+	ml.clearMarks()
+	return func() tview.Msg {
+		if _, err := clipboard.Write(context.Background(), clipboard.FmtText, []byte(transcript)); err != nil {
+			slog.Error("failed to write to clipboard", "err", err)
+		}
+		return nil
+	}
+}
+
+// exportMessages prompts for a file and writes the transcript of the
+// selected messages to it.
+func (ml *messagesList) exportMessages() tview.Cmd {
+	messages := ml.selectedMessages()
+	if len(messages) == 0 {
+		return nil
+	}
+
+	transcript := ml.transcript(messages)
+	cmd := ml.chat.openPrompt("Export messages", "Path", func(path string) tview.Cmd {
+		path = expandHome(strings.TrimSpace(path))
+		if err := os.WriteFile(path, []byte(transcript), 0o644); err != nil {
+			slog.Error("failed to export messages", "err", err, "path", path)
+			return ui.ShowModal("Failed to export messages: "+err.Error(), ui.ModalButton{Label: "Close"})
+		}
+
+		ml.clearMarks()
+		return ui.ShowModal(fmt.Sprintf("Exported %d messages to %s.", len(messages), path), ui.ModalButton{Label: "Close"})
+	})
+
+	name := fmt.Sprintf("discordo-%s-%s.txt", messages[0].ChannelID, time.Now().Format("20060102-150405"))
+	if home, err := os.UserHomeDir(); err == nil {
+		name = filepath.Join(home, name)
+	}
+	ml.chat.prompt.GetFormItem(0).(*tview.InputField).SetText(name)
+	return cmd
+}
+
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~"+string(filepath.Separator))
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+func (ml *messagesList) confirmBulkDelete() tview.Cmd {
+	messages := slices.DeleteFunc(ml.markedMessages(), func(message discord.Message) bool {
+		return !ml.canDeleteMessage(message) || ml.chat.audit.isDeleted(message.ID)
+	})
+	if len(messages) == 0 {
+		return ui.ShowModal("You cannot delete any of the marked messages.", ui.ModalButton{Label: "Close"})
+	}
+
+	return ui.ShowModal(
+		fmt.Sprintf("Are you sure you want to delete %d messages?", len(messages)),
+		ui.ModalButton{Label: "Yes", Result: deleteMessagesMsg(messages)},
+		ui.ModalButton{Label: "No"},
+	)
+}
+
+// deleteMessagesRequest deletes the messages, in bulk where Discord allows:
+// in guild channels where the user may manage messages, for messages younger
+// than two weeks. The rest are deleted one by one.
+func (ml *messagesList) deleteMessagesRequest(messages []discord.Message) tview.Cmd {
+	ml.clearMarks()
+	return func() tview.Msg {
+		var bulk, single []discord.Message
+		for _, message := range messages {
+			if message.GuildID.IsValid() &&
+				ml.chat.state.HasPermissions(message.ChannelID, discord.PermissionManageMessages) &&
+				time.Since(message.ID.Time()) < bulkDeleteMaxAge {
+				bulk = append(bulk, message)
+			} else {
+				single = append(single, message)
+			}
+		}
+
+		// Bulk deletion needs at least two messages.
+		if len(bulk) >= 2 {
+			ids := make([]discord.MessageID, len(bulk))
+			for i, message := range bulk {
+				ids[i] = message.ID
+			}
+			channelID := bulk[0].ChannelID
+			if err := ml.chat.state.DeleteMessages(channelID, ids, ""); err != nil {
+				slog.Error("failed to bulk delete messages; deleting one by one", "err", err, "channel_id", channelID, "count", len(ids))
+				single = append(single, bulk...)
+			} else {
+				for _, id := range ids {
+					if err := ml.chat.state.MessageRemove(channelID, id); err != nil {
+						slog.Error("failed to delete message", "channel_id", channelID, "message_id", id, "err", err)
+					}
+				}
+			}
+		} else {
+			single = append(single, bulk...)
+		}
+
+		for _, message := range single {
+			ml.deleteMessageRequest(message)()
+		}
+		return nil
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ayn2op/discordo/internal/config"
 	"github.com/ayn2op/discordo/internal/consts"
+	"github.com/ayn2op/discordo/internal/keyring"
 	"github.com/ayn2op/discordo/internal/ui"
 	"github.com/ayn2op/discordo/internal/ui/chat"
 	"github.com/ayn2op/discordo/internal/ui/login"
@@ -21,7 +22,6 @@ import (
 )
 
 const (
-	tokenEnvVarKey   = "DISCORDO_TOKEN"
 	contentLayerName = "content"
 	modalLayerName   = "modal"
 )
@@ -102,7 +102,7 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 		m.focused = msg.Model
 	case tview.InitMsg:
 		var cmd tview.Cmd
-		if token := os.Getenv(tokenEnvVarKey); token != "" {
+		if token := os.Getenv(keyring.TokenEnvVarKey); token != "" {
 			cmd = tokenCmd(token)
 		} else {
 			cmd = getToken()
@@ -168,6 +168,14 @@ func (m *Model) Update(msg tview.Msg) tview.Cmd {
 	return nil
 }
 
+// View draws the frame, then the image previews painted over it.
+func (m *Model) View(screen tcell.Screen) {
+	m.Layers.View(screen)
+	if chatModel, ok := m.inner.(*chat.Model); ok {
+		chatModel.DrawImages(screen)
+	}
+}
+
 func (m *Model) showModal(request ui.ModalMsg) tview.Cmd {
 	if m.modalRequest != nil {
 		return nil